@@ -0,0 +1,280 @@
+package faster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Metric -- a value computed from the data of a single History interval (see AlertRule)
+type Metric interface {
+	// Value -- computes the metric for the interval between prev and cur (ok is false if there's no data)
+	Value(cur, prev *Snapshot, path []string) (value float64, ok bool)
+	// Format -- returns a human readable representation of the given value
+	Format(value float64) string
+	String() string
+}
+
+// Percentile -- Metric returning the given percentile of the durations in an interval (in seconds)
+//
+// requires histograms to be enabled
+func Percentile(p int) Metric { return percentileMetric(p) }
+
+// Average -- Metric returning the average duration in an interval (in seconds)
+func Average() Metric { return averageMetric{} }
+
+// Rate -- Metric returning the number of calls per second in an interval
+func Rate() Metric { return rateMetric{} }
+
+type percentileMetric int
+
+func (m percentileMetric) Value(cur, prev *Snapshot, path []string) (float64, bool) {
+	var h = cur.GetHistogram(path...)
+	if h == nil {
+		return 0, false
+	}
+	if old := prev.GetHistogram(path...); old != nil {
+		h = h.Since(*old)
+	}
+	if h.Count() <= 0 {
+		return 0, false
+	}
+	return h.GetPercentile(int(m)).Seconds(), true
+}
+
+func (m percentileMetric) Format(value float64) string { return formatSeconds(value) }
+func (m percentileMetric) String() string              { return fmt.Sprintf("p%d", int(m)) }
+
+type averageMetric struct{}
+
+func (averageMetric) Value(cur, prev *Snapshot, path []string) (float64, bool) {
+	var d = cur.Get(path...)
+	if d == nil {
+		return 0, false
+	}
+	d = d.Sub(prev.Get(path...))
+	if d.Count() <= 0 {
+		return 0, false
+	}
+	return d.Average().Seconds(), true
+}
+
+func (averageMetric) Format(value float64) string { return formatSeconds(value) }
+func (averageMetric) String() string              { return "avg" }
+
+type rateMetric struct{}
+
+func (rateMetric) Value(cur, prev *Snapshot, path []string) (float64, bool) {
+	var interval = cur.TS.Sub(prev.TS).Seconds()
+	if interval <= 0 {
+		return 0, false
+	}
+
+	var count int64
+	if d := cur.Get(path...); d != nil {
+		count = d.Sub(prev.Get(path...)).Count()
+	}
+	return float64(count) / interval, true
+}
+
+func (rateMetric) Format(value float64) string { return fmt.Sprintf("%.2f/s", value) }
+func (rateMetric) String() string              { return "rate" }
+
+func formatSeconds(value float64) string {
+	return time.Duration(value * float64(time.Second)).String()
+}
+
+// Comparison -- the way an AlertRule's Metric is compared to its Threshold
+type Comparison int
+
+const (
+	// Above -- alert if the Metric exceeds the Threshold
+	Above Comparison = iota
+	// Below -- alert if the Metric drops below the Threshold
+	Below
+)
+
+// AlertRule -- fires if the Metric of the given key violates the Threshold for a number of consecutive History ticks
+//
+// e.g. "p99 of http/GET /api > 300ms for 3 consecutive 1sec ticks":
+//
+//	AlertRule{
+//		Name:      "api-latency",
+//		Path:      []string{"http", "GET /api"},
+//		Ticker:    "1sec",
+//		Metric:    faster.Percentile(99),
+//		Threshold: (300 * time.Millisecond).Seconds(),
+//		For:       3,
+//	}
+type AlertRule struct {
+	// Name -- unique name of this rule
+	Name string
+	// Path -- the key to watch
+	Path []string
+	// Ticker -- name of the History ticker this rule will be evaluated on (see Faster.SetTicker())
+	Ticker string
+
+	// Metric -- the value to watch (rules without a Metric never fire)
+	Metric     Metric
+	Comparison Comparison
+	// Threshold -- durations are specified in seconds
+	Threshold float64
+	// For -- number of consecutive ticks the Threshold has to be violated for the alert to fire (defaults to 1)
+	For int
+}
+
+// violated -- returns true if the given value violates the rule's Threshold
+func (r *AlertRule) violated(value float64) bool {
+	if r.Comparison == Below {
+		return value < r.Threshold
+	}
+	return value > r.Threshold
+}
+
+// String -- returns a human readable description of the rule (e.g. "p99 of http | GET /api > 300ms for 3 ticks of 1sec")
+func (r AlertRule) String() string {
+	var op = ">"
+	if r.Comparison == Below {
+		op = "<"
+	}
+	var count = r.For
+	if count < 1 {
+		count = 1
+	}
+	var metric, threshold = "<nil>", fmt.Sprint(r.Threshold)
+	if r.Metric != nil {
+		metric, threshold = r.Metric.String(), r.Metric.Format(r.Threshold)
+	}
+	return fmt.Sprintf("%s of %s %s %s for %d ticks of %s", metric, strings.Join(r.Path, " | "), op, threshold, count, r.Ticker)
+}
+
+// Alert -- the current state of an AlertRule
+type Alert struct {
+	Rule AlertRule
+	// Firing -- true if the rule's Threshold was violated for at least Rule.For consecutive ticks
+	Firing bool
+	// Since -- time of the last state transition (firing/resolved)
+	Since time.Time
+	// Violations -- number of consecutive ticks the rule's Threshold has been violated
+	Violations int
+
+	// Value -- the Metric's latest value (only valid if HasValue is true)
+	Value    float64
+	HasValue bool
+	// LastEval -- time the rule was last evaluated
+	LastEval time.Time
+}
+
+// alertState -- AlertRule + its current state and callback
+type alertState struct {
+	alert    Alert
+	onChange func(Alert)
+}
+
+// evaluate -- updates the alert's state (returns true if the Alert started/stopped firing)
+func (a *alertState) evaluate(cur, prev *Snapshot) bool {
+	var rule = &a.alert.Rule
+	var value float64
+	var ok bool
+	if rule.Metric != nil {
+		value, ok = rule.Metric.Value(cur, prev, rule.Path)
+	}
+
+	a.alert.Value, a.alert.HasValue = value, ok
+	a.alert.LastEval = cur.TS
+	if ok && rule.violated(value) {
+		a.alert.Violations++
+	} else {
+		a.alert.Violations = 0
+	}
+
+	var firing = a.alert.Violations > 0 && a.alert.Violations >= rule.For
+	if firing == a.alert.Firing {
+		return false
+	}
+	a.alert.Firing = firing
+	a.alert.Since = cur.TS
+	return true
+}
+
+// SetAlert -- adds (or replaces) an AlertRule (evaluated each time its History ticker takes a Snapshot)
+//
+// onChange (may be nil) will be called whenever the alert starts firing or is resolved
+// (callbacks are called one after the other in a separate goroutine - so make sure they return quickly).
+// If they don't, pending callbacks queue up - and once that queue is full, new
+// ones will be dropped (see DroppedAlertCallbacks()) instead of blocking Track()/Done().
+func (f *Faster) SetAlert(rule AlertRule, onChange func(Alert)) {
+	f.alertLock.Lock()
+	defer f.alertLock.Unlock()
+
+	f.alerts[rule.Name] = &alertState{
+		alert:    Alert{Rule: rule},
+		onChange: onChange,
+	}
+}
+
+// RemoveAlert -- removes the AlertRule with the given name
+func (f *Faster) RemoveAlert(name string) {
+	f.alertLock.Lock()
+	defer f.alertLock.Unlock()
+
+	delete(f.alerts, name)
+}
+
+// ListAlerts -- returns the current state of all registered AlertRules (sorted by name)
+func (f *Faster) ListAlerts() []Alert {
+	f.alertLock.Lock()
+	defer f.alertLock.Unlock()
+
+	var rc = make([]Alert, 0, len(f.alerts))
+	for _, a := range f.alerts {
+		rc = append(rc, a.alert)
+	}
+	sort.Slice(rc, func(i, j int) bool {
+		return rc[i].Rule.Name < rc[j].Rule.Name
+	})
+	return rc
+}
+
+// evaluateAlerts -- evaluates all AlertRules of the given History ticker (called by the run() goroutine)
+func (f *Faster) evaluateAlerts(ticker string, cur, prev *Snapshot) {
+	if prev == nil {
+		return
+	}
+
+	// callbacks are enqueued after releasing alertLock (they may call ListAlerts() or SetAlert())
+	var callbacks []func()
+	f.alertLock.Lock()
+	for _, a := range f.alerts {
+		if a.alert.Rule.Ticker != ticker {
+			continue
+		}
+		if a.evaluate(cur, prev) && a.onChange != nil {
+			var onChange, alert = a.onChange, a.alert
+			callbacks = append(callbacks, func() { onChange(alert) })
+		}
+	}
+	f.alertLock.Unlock()
+
+	for _, fn := range callbacks {
+		select {
+		case f.alertCallbacks <- fn:
+		default:
+			atomic.AddInt64(&f.droppedAlertCallbacks, 1) // don't block the run() goroutine
+		}
+	}
+}
+
+// DroppedAlertCallbacks -- returns the number of AlertRule callbacks that were dropped because too many of them were pending (see SetAlert())
+func (f *Faster) DroppedAlertCallbacks() int64 {
+	return atomic.LoadInt64(&f.droppedAlertCallbacks)
+}
+
+// runAlertCallbacks -- calls AlertRule callbacks (in the order they were triggered)
+func (f *Faster) runAlertCallbacks() {
+	for fn := range f.alertCallbacks {
+		fn()
+	}
+}
@@ -0,0 +1,160 @@
+package faster
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	f := New()
+	prev := f.TakeSnapshot()
+	prev.TS = time.Unix(1000, 0)
+
+	f.Track("foo").Done()
+	f.Track("foo").Done()
+	cur := f.TakeSnapshot()
+	cur.TS = time.Unix(1002, 0)
+
+	value, ok := Rate().Value(cur, prev, []string{"foo"})
+	assert.True(t, ok)
+	assert.InDelta(t, 1.0, value, 0.001) // 2 calls in 2 seconds
+
+	value, ok = Rate().Value(cur, prev, []string{"bar"})
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, value)
+
+	_, ok = Percentile(99).Value(cur, prev, []string{"bar"})
+	assert.False(t, ok)
+	value, ok = Percentile(99).Value(cur, prev, []string{"foo"})
+	assert.True(t, ok)
+	assert.True(t, value < 0.01)
+
+	_, ok = Average().Value(cur, cur, []string{"foo"})
+	assert.False(t, ok, "no calls in the interval")
+
+	assert.Equal(t, "1.5s", Average().Format(1.5))
+	assert.Equal(t, "2.00/s", Rate().Format(2))
+	assert.Equal(t, "p99", Percentile(99).String())
+}
+
+// fakeMetric -- returns predefined values
+type fakeMetric struct {
+	values []float64
+}
+
+func (m *fakeMetric) Value(cur, prev *Snapshot, path []string) (float64, bool) {
+	var rc = m.values[0]
+	m.values = m.values[1:]
+	return rc, rc >= 0
+}
+func (m *fakeMetric) Format(value float64) string { return formatSeconds(value) }
+func (m *fakeMetric) String() string              { return "fake" }
+
+func TestAlerts(t *testing.T) {
+	f := New()
+
+	var changes = make(chan Alert, 10)
+	var rule = AlertRule{
+		Name:      "latency",
+		Path:      []string{"http", "GET /api"},
+		Ticker:    "1sec",
+		Metric:    &fakeMetric{values: []float64{0.5, 0.4, 0.1, 0.5, 0.5, -1, 0.5, 0.5, 0.5, 0.2}},
+		Threshold: 0.3,
+		For:       3,
+	}
+	assert.Equal(t, "fake of http | GET /api > 300ms for 3 ticks of 1sec", rule.String())
+
+	f.SetAlert(rule, func(a Alert) { changes <- a })
+	f.SetAlert(AlertRule{Name: "other", Ticker: "1min", Metric: Rate()}, nil)
+
+	snap := f.TakeSnapshot()
+	f.evaluateAlerts("1sec", snap, nil) // no previous Snapshot -> won't be evaluated
+	assert.False(t, f.ListAlerts()[0].HasValue)
+
+	var expected = []struct {
+		violations int
+		firing     bool
+	}{
+		{1, false}, {2, false}, {0, false}, // 0.5, 0.4, 0.1
+		{1, false}, {2, false}, {0, false}, // 0.5, 0.5, no data
+		{1, false}, {2, false}, {3, true}, // 0.5, 0.5, 0.5
+		{0, false}, // 0.2
+	}
+	for i, e := range expected {
+		f.evaluateAlerts("1sec", snap, snap)
+		f.evaluateAlerts("1min", snap, snap) // shouldn't affect "latency"
+
+		var alerts = f.ListAlerts()
+		if assert.Len(t, alerts, 2) {
+			assert.Equal(t, "latency", alerts[0].Rule.Name)
+			assert.Equal(t, e.violations, alerts[0].Violations, "tick #%d", i)
+			assert.Equal(t, e.firing, alerts[0].Firing, "tick #%d", i)
+		}
+	}
+
+	// the callback should've been called twice (firing + resolved)
+	var a = <-changes
+	assert.True(t, a.Firing)
+	assert.Equal(t, 0.5, a.Value)
+	a = <-changes
+	assert.False(t, a.Firing)
+	assert.Equal(t, 0.2, a.Value)
+	assert.Empty(t, changes)
+
+	f.RemoveAlert("latency")
+	assert.Len(t, f.ListAlerts(), 1)
+}
+
+func TestRuleBelow(t *testing.T) {
+	var rule = AlertRule{Path: []string{"login"}, Ticker: "1sec", Metric: Rate(), Comparison: Below, Threshold: 1}
+	assert.True(t, rule.violated(0.5))
+	assert.False(t, rule.violated(1))
+	assert.Equal(t, "rate of login < 1.00/s for 1 ticks of 1sec", rule.String())
+
+	var a = alertState{alert: Alert{Rule: rule}}
+	var prev, cur = &Snapshot{TS: time.Unix(0, 0)}, &Snapshot{TS: time.Unix(1, 0)}
+	assert.True(t, a.evaluate(cur, prev), "For defaults to 1 tick")
+	assert.True(t, a.alert.Firing)
+	assert.Equal(t, cur.TS, a.alert.Since)
+}
+
+func TestRuleWithoutMetric(t *testing.T) {
+	var rule = AlertRule{Path: []string{"login"}, Ticker: "1sec", Threshold: 1}
+	assert.Equal(t, "<nil> of login > 1 for 1 ticks of 1sec", rule.String())
+
+	var a = alertState{alert: Alert{Rule: rule}}
+	var prev, cur = &Snapshot{TS: time.Unix(0, 0)}, &Snapshot{TS: time.Unix(1, 0)}
+	assert.False(t, a.evaluate(cur, prev))
+	assert.False(t, a.alert.HasValue)
+}
+
+func TestSlowAlertCallbacks(t *testing.T) {
+	f := New()
+
+	var metric = fakeMetric{}
+	for i := 0; i < 150; i++ {
+		metric.values = append(metric.values, float64((i+1)%2)) // firing, resolved, firing, ...
+	}
+	var unblock = make(chan struct{})
+	var called int64
+	f.SetAlert(AlertRule{Name: "flapping", Ticker: "1sec", Metric: &metric, Threshold: 0.5}, func(a Alert) {
+		<-unblock
+		f.ListAlerts() // callbacks may use the Faster instance
+		atomic.AddInt64(&called, 1)
+	})
+
+	// a slow callback doesn't block evaluation (i.e. the run() goroutine) - excess callbacks are dropped instead
+	var snap = f.TakeSnapshot()
+	for i := 0; i < 150; i++ {
+		f.evaluateAlerts("1sec", snap, snap)
+	}
+	assert.True(t, f.DroppedAlertCallbacks() >= 49, "dropped: %d", f.DroppedAlertCallbacks())
+
+	close(unblock)
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt64(&called)+f.DroppedAlertCallbacks() == 150
+	}, time.Second, time.Millisecond)
+}
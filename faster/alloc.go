@@ -0,0 +1,89 @@
+package faster
+
+import (
+	"runtime"
+
+	"github.com/mreithub/go-faster/faster/internal"
+)
+
+// allocStats -- heap allocation counters (see Faster.TrackAlloc())
+type allocStats struct {
+	objects int64
+	bytes   int64
+}
+
+// readAllocs -- returns the process-wide number of heap allocations (and allocated bytes) so far
+//
+// Uses runtime.ReadMemStats() (which briefly stops the world) - runtime/metrics
+// only updates its allocation counters once per span, which is too coarse for single calls.
+func readAllocs() allocStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return allocStats{
+		objects: int64(m.Mallocs),
+		bytes:   int64(m.TotalAlloc),
+	}
+}
+
+// TrackAlloc -- like Track(), but also records the heap allocations made until Done() is called
+//
+// The number of allocations (and allocated bytes) ends up in the key's
+// DataPoint.Allocs() and AllocBytes() values (AllocCalls() counts the calls they
+// were measured for):
+//
+//	func parse(buf []byte) {
+//		defer faster.Singleton.TrackAlloc("parser", "parse").Done()
+//		// ...
+//	}
+//
+// Accuracy: Go doesn't keep per-goroutine allocation counters, so the
+// process-wide ones are compared. Values are exact if nothing else allocates
+// in the meantime (e.g. in benchmarks or serialized sections like a single
+// worker goroutine), but include other goroutines' allocations otherwise (i.e.
+// they are upper bounds under concurrency).
+//
+// Cost: both TrackAlloc() and Done() call runtime.ReadMemStats(), which stops
+// the world for a few microseconds. Don't use it for hot code paths unless it's
+// sampled (see Sampler.TrackAlloc()). Children (see Tracker.NewChild()) measure
+// the allocations since their parent was created.
+func (f *Faster) TrackAlloc(key ...string) *Tracker {
+	return f.Track(key...).trackAllocs()
+}
+
+// TrackAlloc -- like Track(), but also records heap allocations of the sampled calls (see Faster.TrackAlloc())
+func (s *Sampler) TrackAlloc() *Tracker {
+	return s.Track().trackAllocs()
+}
+
+// trackAllocs -- makes Done() record the heap allocations since now (returns t)
+func (t *Tracker) trackAllocs() *Tracker {
+	if t.Sampled() {
+		var start = readAllocs()
+		t.allocStart = &start
+	}
+	return t
+}
+
+// Allocs -- returns the number of heap allocations (and allocated bytes) between TrackAlloc() and Done()
+//
+// Returns zeros before Done() is called (or if the Tracker wasn't created by TrackAlloc())
+func (t *Tracker) Allocs() (objects, bytes int64) {
+	if t == nil {
+		return 0, 0
+	}
+	return t.allocs.objects, t.allocs.bytes
+}
+
+// onAllocs -- adds the allocations of a TrackAlloc() Tracker to d (called by onDone())
+func (d *data) onAllocs(ev *internal.Event) {
+	var t, ok = ev.Tracker.(*Tracker)
+	if !ok || t.allocStart == nil {
+		return
+	}
+
+	var calls = int64(1 + ev.Value)
+	d.kind |= kindAllocs
+	d.allocCalls += calls
+	d.allocs += t.allocs.objects * calls
+	d.allocBytes += t.allocs.bytes * calls
+}
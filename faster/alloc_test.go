@@ -0,0 +1,58 @@
+package faster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var allocSink []byte
+
+func TestTrackAlloc(t *testing.T) {
+	var f = New()
+	var snap = f.TakeSnapshot()
+
+	var ref = f.TrackAlloc("parse")
+	var child = ref.NewChild("child")
+	allocSink = make([]byte, 4096)
+	child.Done()
+	ref.Done()
+	f.Track("parse").Done() // doesn't count towards AllocCalls()
+
+	// other goroutines (like the one processing events) may allocate as well -> values are lower bounds here
+	var objects, bytes = ref.Allocs()
+	assert.True(t, objects >= 1, "allocs: %d", objects)
+	assert.True(t, bytes >= 4096, "bytes: %d", bytes)
+
+	var d = f.TakeSnapshot().Sub(snap).Get("parse")
+	assert.Equal(t, int64(2), d.Count())
+	assert.Equal(t, int64(1), d.AllocCalls())
+	assert.Equal(t, objects, d.Allocs())
+	assert.Equal(t, bytes, d.AllocBytes())
+	assert.True(t, f.TakeSnapshot().Get("parse", "child").AllocBytes() >= 4096)
+
+	var key = f.TakeSnapshot().JSON().Keys[0]
+	assert.Equal(t, int64(1), key.AllocCalls)
+	assert.Equal(t, bytes, key.AllocBytes)
+
+	// Trackers not created by TrackAlloc() don't measure anything
+	ref = f.Track("plain")
+	allocSink = make([]byte, 4096)
+	ref.Done()
+	objects, bytes = ref.Allocs()
+	assert.Equal(t, int64(0), objects+bytes)
+	assert.Equal(t, int64(0), f.TakeSnapshot().Get("plain").AllocCalls())
+}
+
+func TestSamplerTrackAlloc(t *testing.T) {
+	var f = New()
+	var sampler = f.NewSampler(SampleEvery, 2, "hot")
+	for i := 0; i < 3; i++ {
+		sampler.TrackAlloc().Done()
+	}
+
+	// the second sampled call stands for two calls
+	var d = f.TakeSnapshot().Get("hot")
+	assert.Equal(t, int64(3), d.AllocCalls())
+	assert.Equal(t, d.Count(), d.AllocCalls())
+}
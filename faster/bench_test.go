@@ -21,7 +21,7 @@ func BenchmarkMeasureTime(b *testing.B) {
 
 // BenchmarkTrackDone -- Measures how long an empty Track().Done() call takes
 func BenchmarkTrackDone(b *testing.B) {
-	g := New(true)
+	g := New()
 
 	for n := 0; n < b.N; n++ {
 		g.Track("hello").Done()
@@ -33,7 +33,7 @@ func BenchmarkTrackDone(b *testing.B) {
 
 // BenchmarkTrackDoneDeferred -- Measures how long an empty Track().Done() call takes (doing the Done() in a defer statement)
 func BenchmarkTrackDoneDeferred(b *testing.B) {
-	g := New(true)
+	g := New()
 
 	for n := 0; n < b.N; n++ {
 		r := g.Track("hello")
@@ -44,23 +44,57 @@ func BenchmarkTrackDoneDeferred(b *testing.B) {
 	//log.Printf("data: %s", j)
 }
 
-// benchmarkTakeSnapshot -- Measure how long it takes to create a deep copy of the snapshot data
-func benchmarkTakeSnapshot(count int, b *testing.B) {
+// BenchmarkSampler -- Measures how long Track().Done() takes for a Sampler tracking one in 100 calls
+func BenchmarkSampler(b *testing.B) {
+	g := New()
+	s := g.NewSampler(SampleEvery, 100, "hello")
+
+	for n := 0; n < b.N; n++ {
+		s.Track().Done()
+	}
+}
+
+// benchmarkTakeSnapshot -- Measure how long it takes to create a (copy-on-write) copy of the snapshot data
+//
+// if changes > 0, that many keys will be tracked before each snapshot
+func benchmarkTakeSnapshot(count int, changes int, b *testing.B) {
 	// setup
-	g := New(true)
+	g := New()
+	g.SetLimit(-1)
+	var keys = make([]string, count)
 	for n := 0; n < count; n++ {
-		g.Track(fmt.Sprintf("ref%d", n)).Done()
+		keys[n] = fmt.Sprintf("ref%d", n)
+		g.Track("group", fmt.Sprint(n%100), keys[n]).Done()
 	}
+	g.TakeSnapshot()
+	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
+		for i := 0; i < changes; i++ {
+			var k = (n*changes + i) % count
+			g.Track("group", fmt.Sprint(k%100), keys[k]).Done()
+		}
 		snap = g.TakeSnapshot()
 	}
 }
 
 func BenchmarkTakeSnapshot100(b *testing.B) {
-	benchmarkTakeSnapshot(100, b)
+	benchmarkTakeSnapshot(100, 0, b)
 }
 
 func BenchmarkTakeSnapshot1000(b *testing.B) {
-	benchmarkTakeSnapshot(1000, b)
+	benchmarkTakeSnapshot(1000, 0, b)
+}
+
+func BenchmarkTakeSnapshot10000(b *testing.B) {
+	benchmarkTakeSnapshot(10000, 0, b)
+}
+
+func BenchmarkTakeSnapshot100000(b *testing.B) {
+	benchmarkTakeSnapshot(100000, 0, b)
+}
+
+// BenchmarkTakeSnapshot100000Changed10 -- 100k keys, 10 of which change between two snapshots
+func BenchmarkTakeSnapshot100000Changed10(b *testing.B) {
+	benchmarkTakeSnapshot(100000, 10, b)
 }
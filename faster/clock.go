@@ -0,0 +1,66 @@
+package faster
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock -- source of the timestamps (and periodic ticks) used by Faster (and its Trackers)
+//
+// Defaults to SystemClock. Use WithClock() to provide a different one - e.g.
+// the fake clock in the fastertest package, or a cheaper (coarser) time source
+// if time.Now() turns out to be too slow on your platform.
+type Clock interface {
+	Now() time.Time
+	// NewTicker -- calls fn every interval until the returned Ticker is stopped
+	//
+	// fn may block (e.g. until History snapshots were taken). Fake clocks should
+	// call it synchronously (that way ticks are fully processed once they return)
+	NewTicker(interval time.Duration, fn func(now time.Time)) Ticker
+}
+
+// Ticker -- periodic callback created by Clock.NewTicker()
+type Ticker interface {
+	// Stop -- stops the Ticker (may be called more than once)
+	Stop()
+}
+
+// SystemClock -- Clock using time.Now() and time.Ticker
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTicker(interval time.Duration, fn func(now time.Time)) Ticker {
+	var rc = systemTicker{
+		ticker: time.NewTicker(interval),
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		for {
+			select {
+			case now := <-rc.ticker.C:
+				fn(now)
+			case <-rc.done:
+				return
+			}
+		}
+	}()
+	return &rc
+}
+
+// systemTicker -- Ticker returned by SystemClock
+type systemTicker struct {
+	ticker   *time.Ticker
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func (t *systemTicker) Stop() {
+	t.stopOnce.Do(func() {
+		t.ticker.Stop()
+		close(t.done)
+	})
+}
@@ -0,0 +1,118 @@
+package faster_test
+
+import (
+	"net/http"
+	"net/http/httptrace"
+	"testing"
+	"time"
+
+	"github.com/mreithub/go-faster/faster"
+	"github.com/mreithub/go-faster/faster/fastertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClockHistory(t *testing.T) {
+	var f, clock = fastertest.NewWithClock(t)
+	var start = clock.Now()
+	f.SetTicker("sec", time.Second, 10)
+	var history = f.ListTickers()["sec"]
+	assert.Equal(t, 1, history.Len()) // initial snapshot
+
+	for i := 0; i < 3; i++ {
+		var ref = f.Track("http", "GET /")
+		clock.Advance(time.Duration(i+1) * time.Millisecond)
+		ref.Done()
+		clock.Advance(time.Second - time.Duration(i+1)*time.Millisecond)
+	}
+
+	// ticks are processed synchronously
+	var snapshots = history.List()
+	if assert.Len(t, snapshots, 4) {
+		for i, snap := range snapshots {
+			assert.Equal(t, start.Add(time.Duration(i)*time.Second), snap.TS)
+		}
+	}
+
+	// the initial snapshot (without the key) counts as empty
+	var data = history.GetData("http", "GET /").Relative()
+	if assert.Len(t, data.Data, 3) {
+		assert.Equal(t, int64(1), data.Data[0].Count())
+		assert.Equal(t, time.Millisecond, data.Data[0].TotalTime())
+		assert.Equal(t, 3*time.Millisecond, data.Data[2].TotalTime())
+		assert.Equal(t, start.Add(time.Second), data.GetTimestamp(0))
+	}
+	assert.Equal(t, 6*time.Millisecond, snapshots[3].Get("http", "GET /").TotalTime())
+
+	// a stopped ticker doesn't take any more snapshots
+	f.SetTicker("sec", 0, 0)
+	clock.Advance(time.Minute)
+	assert.Equal(t, 4, history.Len())
+}
+
+func TestFakeClockEvict(t *testing.T) {
+	var f, clock = fastertest.NewWithClock(t)
+	f.SetEvictAfter(time.Minute)
+	f.TakeSnapshot() // wait for the eviction ticker to be set up
+
+	f.Track("old").Done()
+	clock.Advance(45 * time.Second)
+	f.Track("new").Done()
+	clock.Advance(45 * time.Second) // evicted at 90s (idle for more than a minute)
+
+	var snap = f.TakeSnapshot()
+	assert.Nil(t, snap.Get("old"))
+	assert.NotNil(t, snap.Get("new"))
+}
+
+// roundTripFunc -- http.RoundTripper calling a function
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return fn(req) }
+
+func TestFakeClockTransport(t *testing.T) {
+	var f, clock = fastertest.NewWithClock(t)
+	var client = http.Client{Transport: &faster.Transport{
+		Faster: f,
+		Base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			var trace = httptrace.ContextClientTrace(req.Context())
+			clock.Advance(2 * time.Millisecond)
+			trace.GotConn(httptrace.GotConnInfo{})
+			clock.Advance(3 * time.Millisecond)
+			trace.GotFirstResponseByte()
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+		}),
+	}}
+
+	var resp, err = client.Get("http://example.com/")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+	}
+
+	var snap = f.TakeSnapshot()
+	assert.Equal(t, 5*time.Millisecond, snap.Get("http-client", "example.com", "GET").TotalTime())
+	assert.Equal(t, 2*time.Millisecond, snap.Get("http-client", "example.com", "GET", "_newConn").TotalTime())
+	assert.Equal(t, 5*time.Millisecond, snap.Get("http-client", "example.com", "GET", "_ttfb").TotalTime())
+}
+
+func TestEvictValues(t *testing.T) {
+	var f, clock = fastertest.NewWithClock(t, faster.WithRuntimeMetrics())
+	f.SetEvictAfter(time.Minute)
+
+	f.Gauge("config", "workers").Set(8)
+	f.Counter("jobs", "failed").Add(1)
+	f.Track("http", "GET /old").Done()
+	for i := 0; i < 10; i++ {
+		clock.Advance(30 * time.Second)
+		f.TakeSnapshot() // collects the runtime metrics
+	}
+
+	// Counters and Gauges keep their values, runtime metrics are kept as long as they're collected
+	var snap = f.TakeSnapshot()
+	var workers, _ = snap.Get("config", "workers").Gauge()
+	assert.Equal(t, 8.0, workers)
+	assert.Equal(t, int64(1), snap.Get("jobs", "failed").Counter())
+	assert.Nil(t, snap.Get("http", "GET /old"))
+	if _, ok := snap.Get("_runtime", "goroutines").Gauge(); ok {
+		assert.NotNil(t, snap.Get("_runtime", "gc", "pauses"))
+	}
+}
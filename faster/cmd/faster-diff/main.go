@@ -0,0 +1,169 @@
+// faster-diff -- compares two go-faster Snapshot dumps (e.g. before/after a deploy)
+//
+// Usage:
+//
+//	faster-diff [-alpha 0.05] [-avg 10] [-p90 0] [-p99 20] [-min-count 100] old.bin new.bin
+//
+// Prints per-key changes of count, average and p99 (similar to benchstat: '~'
+// marks changes that aren't statistically significant). Exits with status 1 if
+// any of the regression thresholds (relative increases in percent) is exceeded,
+// so it can be used to gate canaries.
+//
+// Dumps are read using faster.ReadSnapshots(). If a dump contains more than one
+// Snapshot (e.g. a History), the difference between its first and last Snapshot
+// is compared.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mreithub/go-faster/faster"
+)
+
+// readDump -- reads the given file ("-" for stdin) and returns the Snapshot to compare
+func readDump(path string) (*faster.Snapshot, error) {
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		var f, err = os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var list, err = faster.ReadSnapshots(in)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	} else if len(list) == 0 {
+		return nil, fmt.Errorf("%s: no snapshots found", path)
+	}
+
+	var first, last = list[0], list[len(list)-1]
+	if len(list) == 1 {
+		return last, nil
+	}
+	return last.Sub(first), nil
+}
+
+// formatDuration -- formats durations for the table (rounded to three significant digits)
+func formatDuration(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d < time.Microsecond:
+		return d.String()
+	case d < time.Millisecond:
+		return fmt.Sprintf("%.1fµs", float64(d)/float64(time.Microsecond))
+	case d < time.Second:
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	}
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
+
+// formatDelta -- formats a relative change ('~' if it's not significant)
+func formatDelta(change float64, significant bool) string {
+	if math.IsNaN(change) {
+		return "?"
+	} else if !significant {
+		return "~"
+	}
+	return fmt.Sprintf("%+.2f%%", change)
+}
+
+// writeDiff -- writes the given KeyDiffs as table
+func writeDiff(w io.Writer, diffs []faster.KeyDiff, alpha float64) error {
+	var tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "key\told count\tnew count\tdelta\told avg\tnew avg\tdelta\told p99\tnew p99\tdelta")
+
+	for _, d := range diffs {
+		var oldCount, newCount int64
+		var oldAvg, newAvg, oldP99, newP99 time.Duration
+		if d.Old != nil {
+			oldCount, oldAvg = d.Old.Count(), d.Old.Average()
+		}
+		if d.New != nil {
+			newCount, newAvg = d.New.Count(), d.New.Average()
+		}
+		if d.OldPercentiles != nil {
+			oldP99 = d.OldPercentiles[2]
+		}
+		if d.NewPercentiles != nil {
+			newP99 = d.NewPercentiles[2]
+		}
+
+		// durations without histograms can't be checked for significance
+		var significant = math.IsNaN(d.P) || d.Significant(alpha)
+		var hint string
+		if !math.IsNaN(d.P) {
+			hint = fmt.Sprintf("(p=%.3f n=%d+%d)", d.P, d.OldN, d.NewN)
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			strings.Join(d.Path, " | "),
+			oldCount, newCount, formatDelta(d.CountDelta(), true),
+			formatDuration(oldAvg), formatDuration(newAvg), formatDelta(d.AverageDelta(), significant),
+			formatDuration(oldP99), formatDuration(newP99), formatDelta(d.PercentileDelta(99), significant),
+			hint)
+	}
+	return tw.Flush()
+}
+
+// run -- implements the command (returning the exit status)
+func run(args []string, stdout, stderr io.Writer) int {
+	var flags = flag.NewFlagSet("faster-diff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var t faster.Thresholds
+	flags.Float64Var(&t.Alpha, "alpha", 0.05, "significance level (duration changes with higher p-values are ignored)")
+	flags.Float64Var(&t.Average, "avg", 0, "max. increase of the average duration (in percent, 0: don't check)")
+	flags.Float64Var(&t.P90, "p90", 0, "max. increase of the p90 duration (in percent, 0: don't check)")
+	flags.Float64Var(&t.P99, "p99", 0, "max. increase of the p99 duration (in percent, 0: don't check)")
+	flags.Int64Var(&t.MinCount, "min-count", 0, "ignore keys with fewer calls when checking thresholds")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: faster-diff [flags] <old dump> <new dump>\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	} else if flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+
+	older, err := readDump(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	newer, err := readDump(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	var diffs = faster.Compare(older, newer)
+	if err = writeDiff(stdout, diffs, t.Alpha); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	var rc = 0
+	for _, d := range diffs {
+		for _, msg := range d.Regressions(t) {
+			fmt.Fprintf(stderr, "regression: %s: %s\n", strings.Join(d.Path, " | "), msg)
+			rc = 1
+		}
+	}
+	return rc
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
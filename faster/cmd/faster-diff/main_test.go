@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mreithub/go-faster/faster"
+	"github.com/mreithub/go-faster/faster/fastertest"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeDump -- writes the given Snapshots to a file in dir (returning its path)
+func writeDump(t *testing.T, dir, name string, snapshots ...*faster.Snapshot) string {
+	var path = filepath.Join(dir, name)
+	var file, err = os.Create(path)
+	if assert.NoError(t, err) {
+		assert.NoError(t, faster.NewEncoder(file).EncodeAll(snapshots))
+		file.Close()
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	var dir, err = ioutil.TempDir("", "faster-diff")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	var before, beforeClock = fastertest.NewWithClock(t)
+	var after, afterClock = fastertest.NewWithClock(t)
+	var start = before.TakeSnapshot()
+	for i := 0; i < 10; i++ {
+		var ref = before.Track("http", "GET /")
+		beforeClock.Advance(100 * time.Microsecond)
+		ref.Done()
+
+		ref = after.Track("http", "GET /")
+		afterClock.Advance(2 * time.Millisecond)
+		ref.Done()
+	}
+	var oldPath = writeDump(t, dir, "old.bin", start, before.TakeSnapshot())
+	var newPath = writeDump(t, dir, "new.bin", after.TakeSnapshot())
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 0, run([]string{oldPath, newPath}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "http | GET /")
+	assert.Contains(t, stdout.String(), "(p=0.000 n=10+10)")
+	assert.Empty(t, stderr.String())
+
+	stdout.Reset()
+	assert.Equal(t, 1, run([]string{"-avg", "50", oldPath, newPath}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "regression: http | GET /: avg +")
+
+	// not enough calls
+	stderr.Reset()
+	assert.Equal(t, 0, run([]string{"-avg", "50", "-min-count", "20", oldPath, newPath}, &stdout, &stderr))
+	assert.Empty(t, stderr.String())
+
+	// no regression the other way round
+	assert.Equal(t, 0, run([]string{"-avg", "50", "-p99", "10", newPath, oldPath}, &stdout, &stderr))
+
+	assert.Equal(t, 2, run([]string{oldPath}, &stdout, &stderr))
+	assert.Equal(t, 2, run([]string{oldPath, oldPath + ".missing"}, &stdout, &stderr))
+}
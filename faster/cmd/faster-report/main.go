@@ -0,0 +1,109 @@
+// faster-report -- renders HTML or Markdown reports from saved go-faster Snapshots
+//
+// Usage:
+//
+//	faster-report [-format html|md] [-o report.html] [-title name] [-top 20] dump.bin...
+//
+// Input files either contain a stream of Snapshots (as written by faster.Encoder,
+// e.g. a History dump) or a single binary encoded Snapshot (see
+// Snapshot.MarshalBinary()). Use "-" to read from stdin.
+//
+// To dump a History:
+//
+//	var file, _ = os.Create("dump.bin")
+//	faster.NewEncoder(file).EncodeAll(faster.Singleton.ListTickers()["sec"].List())
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mreithub/go-faster/faster"
+	"github.com/mreithub/go-faster/faster/dashboard"
+)
+
+// readFile -- reads the Snapshots stored in the given file ("-" for stdin)
+func readFile(path string) (faster.Snapshots, error) {
+	if path == "-" {
+		return faster.ReadSnapshots(os.Stdin)
+	}
+
+	var f, err = os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rc, err := faster.ReadSnapshots(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return rc, nil
+}
+
+// writeReport -- writes the report in the given format ("html" or "md")
+func writeReport(w io.Writer, report *dashboard.Report, format string) error {
+	switch format {
+	case "html":
+		return report.WriteHTML(w)
+	case "md", "markdown":
+		return report.WriteMarkdown(w)
+	}
+	return fmt.Errorf("unsupported format: '%s'", format)
+}
+
+func main() {
+	var format = flag.String("format", "", "output format: 'html' or 'md' (default: derived from -o, html otherwise)")
+	var outPath = flag.String("o", "-", "output file ('-' for stdout)")
+	var title = flag.String("title", "", "report title (defaults to the input file names)")
+	var top = flag.Int("top", 20, "number of keys to list (0: all)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <snapshot files...>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *format == "" {
+		*format = "html"
+		if ext := filepath.Ext(*outPath); ext == ".md" || ext == ".markdown" {
+			*format = "md"
+		}
+	}
+	if *title == "" {
+		*title = strings.Join(flag.Args(), ", ")
+	}
+
+	var snapshots faster.Snapshots
+	for _, path := range flag.Args() {
+		var list, err = readFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		snapshots = append(snapshots, list...)
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "-" {
+		var f, err = os.Create(*outPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	var report = dashboard.NewReport(*title, snapshots, *top)
+	if err := writeReport(out, report, *format); err != nil {
+		log.Fatal(err)
+	}
+}
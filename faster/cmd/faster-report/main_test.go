@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mreithub/go-faster/faster"
+	"github.com/mreithub/go-faster/faster/dashboard"
+	"github.com/stretchr/testify/assert"
+)
+
+// testSnapshots -- returns three Snapshots of a Faster instance (with increasing counts)
+func testSnapshots() faster.Snapshots {
+	var f = faster.New()
+	var rc faster.Snapshots
+	for i := 0; i < 3; i++ {
+		for j := 0; j <= i; j++ {
+			f.Track("http", "GET /").Done()
+		}
+		f.Track("db", "query|insert").Done()
+		rc = append(rc, f.TakeSnapshot())
+	}
+	return rc
+}
+
+func TestReadFile(t *testing.T) {
+	var file, err = ioutil.TempFile("", "faster-report")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.Remove(file.Name())
+	assert.NoError(t, faster.NewEncoder(file).EncodeAll(testSnapshots()))
+	file.Close()
+
+	list, err := readFile(file.Name())
+	assert.NoError(t, err)
+	if assert.Len(t, list, 3) {
+		assert.Equal(t, int64(6), list[2].Get("http", "GET /").Count())
+	}
+
+	_, err = readFile(file.Name() + ".missing")
+	assert.Error(t, err)
+}
+
+func TestReport(t *testing.T) {
+	var report = dashboard.NewReport("incident", testSnapshots(), 10)
+	assert.Len(t, report.Intervals, 2)
+
+	var out bytes.Buffer
+	assert.NoError(t, writeReport(&out, report, "md"))
+	var md = out.String()
+	assert.Contains(t, md, "# go-faster report: incident")
+	assert.Contains(t, md, "| http \\| GET / | 5 |")
+	assert.Contains(t, md, "| db \\| query\\|insert | 2 |")
+	assert.Contains(t, md, "## Percentiles")
+	assert.Contains(t, md, "2 intervals")
+
+	out.Reset()
+	assert.NoError(t, writeReport(&out, report, "html"))
+	assert.Contains(t, out.String(), "<h1>go-faster report: incident</h1>")
+	assert.Contains(t, out.String(), "<svg class=\"chart\"")
+
+	assert.Error(t, writeReport(&out, report, "pdf"))
+
+	// empty reports shouldn't fail either
+	out.Reset()
+	assert.NoError(t, writeReport(&out, dashboard.NewReport("empty", nil, 10), "html"))
+	assert.NoError(t, writeReport(&out, dashboard.NewReport("empty", nil, 10), "md"))
+}
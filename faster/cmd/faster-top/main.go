@@ -0,0 +1,184 @@
+// faster-top -- live terminal view of a go-faster dashboard
+//
+// Usage:
+//
+//	faster-top [-interval 2s] [-sort c] [-once] http://localhost:8080/_faster/
+//
+// Polls the dashboard's snapshot.json and shows a sortable table of all keys
+// (with count/s, average, p99 and active calls). Press enter to see the selected
+// key's recent History (using key/info.json).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mreithub/go-faster/faster"
+)
+
+type app struct {
+	client *client
+	url    string
+	view   view
+
+	prev, cur faster.SnapshotJSON
+	rows      []row
+
+	// details -- the key we're showing the details of (nil: show the key table)
+	details []string
+	info    keyInfo
+	err     error
+}
+
+// refresh -- fetches new data
+func (a *app) refresh() {
+	if a.details != nil {
+		a.info, a.err = a.client.keyInfo(a.details)
+		return
+	}
+
+	var snap, err = a.client.snapshot()
+	if a.err = err; err != nil {
+		return
+	}
+	a.prev, a.cur = a.cur, snap
+	a.rows = computeRows(a.prev, a.cur)
+	a.sort()
+}
+
+func (a *app) sort() {
+	sortRows(a.rows, a.view.sortBy, a.view.asc)
+	if a.view.selected >= len(a.rows) {
+		a.view.selected = len(a.rows) - 1
+	}
+	if a.view.selected < 0 {
+		a.view.selected = 0
+	}
+}
+
+// render -- redraws the screen
+func (a *app) render() {
+	var buff strings.Builder
+	buff.WriteString("\x1b[H\x1b[2J")
+	if a.details != nil {
+		renderKey(&buff, a.details, a.info, &a.view)
+	} else {
+		renderTable(&buff, a.url, a.cur.TS, a.rows, &a.view)
+	}
+	if a.err != nil {
+		fmt.Fprintf(&buff, "\r\nError: %s\r\n", a.err)
+	}
+	os.Stdout.WriteString(buff.String())
+}
+
+// onKey -- handles a key press (returns false to quit)
+func (a *app) onKey(key rune) bool {
+	switch {
+	case key == 'q':
+		return false
+	case a.details != nil:
+		if key == keyEsc || key == keyBack || key == 'h' {
+			a.details = nil
+			a.refresh()
+		}
+	case key == keyUp || key == 'k':
+		if a.view.selected > 0 {
+			a.view.selected--
+		}
+	case key == keyDown || key == 'j':
+		if a.view.selected < len(a.rows)-1 {
+			a.view.selected++
+		}
+	case key == keyEnter || key == 'l':
+		if a.view.selected < len(a.rows) {
+			a.details = a.rows[a.view.selected].Path
+			a.refresh()
+		}
+	case getColumn(key) != nil:
+		if a.view.sortBy == key {
+			a.view.asc = !a.view.asc
+		} else {
+			a.view.sortBy, a.view.asc = key, false
+		}
+		a.sort()
+	}
+	return true
+}
+
+func main() {
+	var interval = flag.Duration("interval", 2*time.Second, "refresh interval")
+	var sortBy = flag.String("sort", "c", "initial sort column (one of 'a', 'c', 'v', 'p', 'n', 't')")
+	var once = flag.Bool("once", false, "print the key table once (non-interactive)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <dashboard URL>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 || len(*sortBy) != 1 || getColumn(rune((*sortBy)[0])) == nil {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var c, err = newClient(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	var a = app{
+		client: c,
+		url:    flag.Arg(0),
+		view:   view{sortBy: rune((*sortBy)[0]), width: 120, height: 1 << 20},
+	}
+
+	if *once {
+		// count/s needs two snapshots
+		a.refresh()
+		time.Sleep(*interval)
+		if a.refresh(); a.err != nil {
+			log.Fatal(a.err)
+		}
+		a.view.selected = -1
+		var buff strings.Builder
+		renderTable(&buff, a.url, a.cur.TS, a.rows, &a.view)
+		os.Stdout.WriteString(strings.Replace(buff.String(), "\r\n", "\n", -1))
+		return
+	}
+
+	term, err := openTerminal()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer term.Close()
+
+	// cbreak mode keeps Ctrl-C working -> restore the terminal (by returning) before exiting
+	var signals = make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	var keys = make(chan rune)
+	go readKeys(keys)
+	var ticker = time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	a.refresh()
+	for {
+		a.view.width, a.view.height = term.size()
+		a.render()
+
+		select {
+		case <-ticker.C:
+			a.refresh()
+		case key, ok := <-keys:
+			if !ok || !a.onKey(key) {
+				return
+			}
+		case <-signals:
+			return
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// terminal -- minimal raw mode terminal handling (using stty to avoid non-stdlib dependencies)
+type terminal struct {
+	oldState string
+}
+
+func stty(args ...string) (string, error) {
+	var cmd = exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	var out, err = cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// openTerminal -- switches the terminal to cbreak mode (and hides the cursor)
+func openTerminal() (*terminal, error) {
+	var state, err = stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get terminal state (is stdin a terminal?): %s", err)
+	}
+	if _, err = stty("cbreak", "-echo"); err != nil {
+		return nil, err
+	}
+	fmt.Print("\x1b[?25l")
+	return &terminal{oldState: state}, nil
+}
+
+// Close -- restores the terminal state
+func (t *terminal) Close() {
+	fmt.Print("\x1b[?25h\r\n")
+	stty(t.oldState)
+}
+
+// size -- returns the terminal's dimensions (or 80x24 if unknown)
+func (t *terminal) size() (width, height int) {
+	width, height = 80, 24
+	if out, err := stty("size"); err == nil {
+		fmt.Sscan(out, &height, &width)
+	}
+	return width, height
+}
+
+// key codes for non-printable keys
+const (
+	keyUp    = -1
+	keyDown  = -2
+	keyEnter = '\n'
+	keyEsc   = 27
+	keyBack  = 127
+)
+
+// readKeys -- reads key presses from stdin (translating arrow key escape sequences)
+func readKeys(keys chan<- rune) {
+	var in = bufio.NewReader(os.Stdin)
+	for {
+		var r, _, err = in.ReadRune()
+		if err != nil {
+			close(keys)
+			return
+		}
+
+		if r == keyEsc && in.Buffered() >= 2 {
+			var seq = make([]byte, 2)
+			in.Read(seq)
+			switch string(seq) {
+			case "[A":
+				r = keyUp
+			case "[B":
+				r = keyDown
+			}
+		} else if r == '\r' {
+			r = keyEnter
+		} else if r == '\b' {
+			r = keyBack
+		}
+		keys <- r
+	}
+}
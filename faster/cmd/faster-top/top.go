@@ -0,0 +1,339 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mreithub/go-faster/faster"
+)
+
+// client -- fetches data from a go-faster dashboard's JSON endpoints
+type client struct {
+	base *url.URL
+	http *http.Client
+}
+
+func newClient(dashboardURL string) (*client, error) {
+	var base, err = url.Parse(dashboardURL)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	return &client{
+		base: base,
+		http: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (c *client) get(ref *url.URL, target interface{}) error {
+	var resp, err = c.http.Get(c.base.ResolveReference(ref).String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", ref, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+// snapshot -- fetches snapshot.json
+func (c *client) snapshot() (faster.SnapshotJSON, error) {
+	var rc faster.SnapshotJSON
+	var err = c.get(&url.URL{Path: "snapshot.json"}, &rc)
+	return rc, err
+}
+
+// keyInfo -- the parts of key/info.json we're interested in
+type keyInfo struct {
+	Requests struct {
+		TS      []int64 `json:"ts"`
+		Counts  []int64 `json:"counts"`
+		AvgMsec []int64 `json:"avgMsec"`
+	} `json:"requests"`
+	Tickers []struct {
+		Name     string `json:"name"`
+		Interval string `json:"interval"`
+	} `json:"tickers"`
+
+	Active int32 `json:"active"`
+	Total  int64 `json:"total"`
+	AvgMS  int64 `json:"avgMS"`
+}
+
+// keyInfo -- fetches key/info.json for the given key
+func (c *client) keyInfo(path []string) (keyInfo, error) {
+	var rc keyInfo
+	var err = c.get(&url.URL{
+		Path:     "key/info.json",
+		RawQuery: url.Values{"k": path}.Encode(),
+	}, &rc)
+	return rc, err
+}
+
+// row -- a single line of the key table
+type row struct {
+	Path   []string
+	Active int32
+	Count  int64
+	// Rate -- calls per second (since the previous snapshot)
+	Rate  float64
+	Avg   time.Duration
+	P99   time.Duration
+	Total time.Duration
+}
+
+// Name -- returns the row's key (joined by " | ", as in the dashboard's key pages)
+func (r *row) Name() string {
+	return strings.Join(r.Path, " | ")
+}
+
+// computeRows -- builds the key table from two consecutive snapshots (prev may be empty)
+func computeRows(prev, cur faster.SnapshotJSON) []row {
+	var prevCounts = make(map[string]int64, len(prev.Keys))
+	for _, k := range prev.Keys {
+		prevCounts[strings.Join(k.Path, "\x00")] = k.Count
+	}
+	var interval = cur.TS.Sub(prev.TS).Seconds()
+
+	var rc = make([]row, 0, len(cur.Keys))
+	for _, k := range cur.Keys {
+		var r = row{
+			Path:   k.Path,
+			Active: k.Active,
+			Count:  k.Count,
+			Avg:    k.Average,
+			P99:    k.P99,
+			Total:  k.TotalTime,
+		}
+		if old, ok := prevCounts[strings.Join(k.Path, "\x00")]; ok && !prev.TS.IsZero() && interval > 0 {
+			var diff = k.Count - old
+			if diff < 0 {
+				diff = k.Count // reset
+			}
+			r.Rate = float64(diff) / interval
+		}
+		rc = append(rc, r)
+	}
+	return rc
+}
+
+// column -- a sortable table column
+type column struct {
+	key   rune
+	title string
+	width int
+	less  func(a, b *row) bool
+	value func(r *row) string
+}
+
+var columns = []column{
+	{'a', "active", 7, func(a, b *row) bool { return a.Active < b.Active }, func(r *row) string { return fmt.Sprint(r.Active) }},
+	{'c', "count/s", 9, func(a, b *row) bool { return a.Rate < b.Rate }, func(r *row) string { return fmt.Sprintf("%.1f", r.Rate) }},
+	{'v', "avg", 10, func(a, b *row) bool { return a.Avg < b.Avg }, func(r *row) string { return formatDuration(r.Avg) }},
+	{'p', "p99", 10, func(a, b *row) bool { return a.P99 < b.P99 }, func(r *row) string { return formatDuration(r.P99) }},
+	{'n', "count", 10, func(a, b *row) bool { return a.Count < b.Count }, func(r *row) string { return fmt.Sprint(r.Count) }},
+	{'t', "total", 10, func(a, b *row) bool { return a.Total < b.Total }, func(r *row) string { return formatDuration(r.Total) }},
+}
+
+// getColumn -- returns the column with the given shortcut (or nil)
+func getColumn(key rune) *column {
+	for i := range columns {
+		if columns[i].key == key {
+			return &columns[i]
+		}
+	}
+	return nil
+}
+
+// sortRows -- sorts by the given column (descending unless asc is set - rows with equal values are sorted by name)
+func sortRows(rows []row, sortBy rune, asc bool) {
+	var col = getColumn(sortBy)
+	sort.SliceStable(rows, func(i, j int) bool {
+		var a, b = &rows[i], &rows[j]
+		if col != nil {
+			if col.less(a, b) {
+				return asc
+			} else if col.less(b, a) {
+				return !asc
+			}
+		}
+		return a.Name() < b.Name()
+	})
+}
+
+// formatDuration -- formats durations for the table (rounded to three significant digits)
+func formatDuration(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d < time.Microsecond:
+		return d.String()
+	case d < time.Millisecond:
+		return fmt.Sprintf("%.1fµs", float64(d)/float64(time.Microsecond))
+	case d < time.Second:
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	}
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
+
+// view -- UI state
+type view struct {
+	sortBy   rune
+	asc      bool
+	selected int
+	offset   int
+
+	width, height int
+}
+
+// renderTable -- writes the key table (highlighting the selected row and scrolling if necessary)
+func renderTable(w io.Writer, url string, ts time.Time, rows []row, v *view) {
+	var nameWidth = v.width
+	for _, col := range columns {
+		nameWidth -= col.width + 1
+	}
+	if nameWidth < 10 {
+		nameWidth = 10
+	}
+
+	fmt.Fprintf(w, "faster-top -- %s -- %s\r\n", url, ts.Format("15:04:05"))
+	fmt.Fprintf(w, "sort: %s, [q]uit, [enter]: details, [j/k]: select\r\n\r\n", sortHint())
+
+	var header strings.Builder
+	fmt.Fprintf(&header, "%-*s", nameWidth, "key")
+	for _, col := range columns {
+		var title = col.title
+		if col.key == v.sortBy {
+			if v.asc {
+				title += "▲"
+			} else {
+				title += "▼"
+			}
+		}
+		fmt.Fprintf(&header, " %*s", col.width, title)
+	}
+	fmt.Fprintf(w, "\x1b[1m%s\x1b[0m\r\n", header.String())
+
+	// keep the selected row visible
+	var visible = v.height - 5
+	if visible < 1 {
+		visible = 1
+	}
+	if v.selected < v.offset {
+		v.offset = v.selected
+	} else if v.selected >= v.offset+visible {
+		v.offset = v.selected - visible + 1
+	}
+	if v.offset < 0 {
+		v.offset = 0
+	}
+
+	for i := v.offset; i < len(rows) && i < v.offset+visible; i++ {
+		var r = &rows[i]
+		var name = r.Name()
+		if len([]rune(name)) > nameWidth {
+			name = string([]rune(name)[:nameWidth-1]) + "…"
+		}
+
+		var line strings.Builder
+		fmt.Fprintf(&line, "%-*s", nameWidth, name)
+		for _, col := range columns {
+			fmt.Fprintf(&line, " %*s", col.width, col.value(r))
+		}
+		if i == v.selected {
+			fmt.Fprintf(w, "\x1b[7m%s\x1b[0m\r\n", line.String())
+		} else {
+			fmt.Fprintf(w, "%s\r\n", line.String())
+		}
+	}
+}
+
+func sortHint() string {
+	var parts = make([]string, 0, len(columns))
+	for _, col := range columns {
+		parts = append(parts, fmt.Sprintf("[%c]%s", col.key, col.title))
+	}
+	return strings.Join(parts, " ")
+}
+
+// sparkline -- renders the given values using unicode block characters
+func sparkline(values []int64) string {
+	const ticks = "▁▂▃▄▅▆▇█"
+	var runes = []rune(ticks)
+	if len(values) == 0 {
+		return ""
+	}
+
+	var min, max = values[0], values[0]
+	for _, v := range values {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+
+	var rc strings.Builder
+	for _, v := range values {
+		var i = 0
+		if max > min {
+			i = int((v - min) * int64(len(runes)-1) / (max - min))
+		}
+		rc.WriteRune(runes[i])
+	}
+	return rc.String()
+}
+
+// lastN -- returns (up to) the last n values
+func lastN(values []int64, n int) []int64 {
+	if n > 0 && len(values) > n {
+		return values[len(values)-n:]
+	}
+	return values
+}
+
+// renderKey -- writes the details of a single key (with sparklines of its recent History)
+func renderKey(w io.Writer, path []string, info keyInfo, v *view) {
+	fmt.Fprintf(w, "faster-top -- %s\r\n", strings.Join(path, " | "))
+	fmt.Fprintf(w, "[esc/backspace]: back, [q]uit\r\n\r\n")
+	fmt.Fprintf(w, "active: %d, total: %d, avg: %dms\r\n\r\n", info.Active, info.Total, info.AvgMS)
+
+	if len(info.Tickers) == 0 {
+		fmt.Fprintf(w, "(no History tickers configured - see Faster.SetTicker())\r\n")
+		return
+	}
+
+	var width = v.width - 12
+	var counts, avgs = lastN(info.Requests.Counts, width), lastN(info.Requests.AvgMsec, width)
+	fmt.Fprintf(w, "ticker: %s (%s)\r\n", info.Tickers[0].Name, info.Tickers[0].Interval)
+	fmt.Fprintf(w, "count    %s %s\r\n", sparkline(counts), minMax(counts, ""))
+	fmt.Fprintf(w, "avg      %s %s\r\n", sparkline(avgs), minMax(avgs, "ms"))
+}
+
+// minMax -- returns a "(min..max)" hint for the given values
+func minMax(values []int64, unit string) string {
+	if len(values) == 0 {
+		return "(no data)"
+	}
+	var min, max = values[0], values[0]
+	for _, v := range values {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return fmt.Sprintf("(%d%s..%d%s)", min, unit, max, unit)
+}
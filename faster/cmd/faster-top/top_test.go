@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mreithub/go-faster/faster"
+	"github.com/mreithub/go-faster/faster/dashboard"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient(t *testing.T) {
+	var f = faster.New()
+	f.SetTicker("sec", time.Second, 60)
+	var srv = httptest.NewServer(dashboard.New(f))
+	defer srv.Close()
+
+	f.Track("http", "GET /").Done()
+	f.Track("http", "GET /").Done()
+	var tracker = f.Track("db", "query")
+	defer tracker.Done()
+
+	var c, err = newClient(srv.URL)
+	assert.NoError(t, err)
+
+	snap, err := c.snapshot()
+	assert.NoError(t, err)
+	var rows = computeRows(faster.SnapshotJSON{}, snap)
+	sortRows(rows, 'n', false)
+	if assert.True(t, len(rows) >= 2) {
+		assert.Equal(t, []string{"http", "GET /"}, rows[0].Path)
+		assert.Equal(t, int64(2), rows[0].Count)
+		assert.Equal(t, 0.0, rows[0].Rate)
+	}
+
+	info, err := c.keyInfo([]string{"http", "GET /"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), info.Total)
+	assert.Equal(t, "sec", info.Tickers[0].Name)
+
+	_, err = c.keyInfo(nil)
+	assert.Error(t, err)
+}
+
+func TestComputeRows(t *testing.T) {
+	var ts = time.Now()
+	var prev = faster.SnapshotJSON{TS: ts, Keys: []faster.KeyJSON{
+		{Path: []string{"a"}, Count: 10},
+		{Path: []string{"b"}, Count: 100},
+	}}
+	var cur = faster.SnapshotJSON{TS: ts.Add(2 * time.Second), Keys: []faster.KeyJSON{
+		{Path: []string{"a"}, Count: 30, Active: 3, P99: time.Millisecond},
+		{Path: []string{"b"}, Count: 4}, // reset
+		{Path: []string{"c"}, Count: 5},
+	}}
+
+	var rows = computeRows(prev, cur)
+	sortRows(rows, 'c', false)
+	assert.Equal(t, "a", rows[0].Name())
+	assert.Equal(t, 10.0, rows[0].Rate)
+	assert.Equal(t, "b", rows[1].Name())
+	assert.Equal(t, 2.0, rows[1].Rate)
+	assert.Equal(t, "c", rows[2].Name())
+	assert.Equal(t, 0.0, rows[2].Rate)
+
+	sortRows(rows, 'n', true)
+	assert.Equal(t, "b", rows[0].Name())
+
+	var out strings.Builder
+	var v = view{sortBy: 'n', asc: true, width: 100, height: 6}
+	v.selected = 2
+	renderTable(&out, "http://localhost/", cur.TS, rows, &v)
+	assert.Contains(t, out.String(), "count▲")
+	assert.Contains(t, out.String(), "\x1b[7ma ")
+	assert.NotContains(t, out.String(), "\nb ")
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "", sparkline(nil))
+	assert.Equal(t, "▁▁▁", sparkline([]int64{3, 3, 3}))
+	assert.Equal(t, "▁▄█", sparkline([]int64{0, 5, 10}))
+	assert.Equal(t, []int64{2, 3}, lastN([]int64{1, 2, 3}, 2))
+}
@@ -0,0 +1,374 @@
+// Package collector -- aggregates go-faster Snapshots of many processes (e.g. the replicas of a service)
+//
+// Each process uses a Pusher to periodically send Snapshots of its Faster instance
+// to a central Collector, which merges them (see faster.Snapshots.Merge()) and
+// serves the go-faster dashboard for the merged view (with per-instance drill-down
+// at /instances/).
+//
+// Collector side:
+//
+//	var c = collector.New(10*time.Second, 360) // keep an hour of 10sec snapshots
+//	http.ListenAndServe(":8080", c)
+//
+// Process side:
+//
+//	var p, err = collector.NewPusher(faster.Singleton, "http://collector:8080/", "") // instance name defaults to the hostname
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	p.Start(10 * time.Second)
+package collector
+
+import (
+	"html/template"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mreithub/go-faster/faster"
+	"github.com/mreithub/go-faster/faster/dashboard"
+)
+
+// maxPushSize -- upper limit for the size of pushed Snapshots
+const maxPushSize = 64 << 20
+
+// Collector -- http.Handler receiving Snapshots from Pushers (and serving dashboards for them)
+//
+// Endpoints:
+//
+// - POST /push?instance=<name>&start=<id>: receives a binary encoded Snapshot (see Pusher)
+// - GET /instances/: lists all known instances
+// - /instances/<name>/: dashboard of a single instance
+// - everything else: dashboard of the merged view
+type Collector struct {
+	interval time.Duration
+	keep     int
+	// expire -- instances that haven't pushed any data for this long will be removed (0: never, see WithExpire())
+	expire time.Duration
+	// self -- tracks the dashboard's requests (and incoming pushes)
+	self   *faster.Faster
+	mux    *http.ServeMux
+	merged *view
+
+	lock      sync.Mutex
+	instances map[string]*instance
+	// expired -- the merged totals of all expired instances (see Snapshot.Totals())
+	expired *faster.Snapshot
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// instance -- the latest data of a single process
+type instance struct {
+	name     string
+	snapshot *faster.Snapshot
+	// start -- identifies the pushing process (its Faster instance's StartTS, changes when it's restarted)
+	start string
+	// base -- the totals of the instance's previous runs (nil unless it was restarted)
+	base     *faster.Snapshot
+	lastPush time.Time
+	view     *view
+}
+
+// current -- returns the instance's latest Snapshot (including the totals of its previous runs)
+func (i *instance) current() *faster.Snapshot {
+	if i.base == nil {
+		return i.snapshot
+	}
+	var rc = faster.Snapshots{i.base, i.snapshot}.Merge()
+	rc.TS = i.snapshot.TS
+	return rc
+}
+
+// view -- dashboard.Source for the merged view (or a single instance)
+type view struct {
+	c         *Collector
+	instance  string // empty for the merged view
+	history   *faster.History
+	dashboard *dashboard.Dashboard
+}
+
+func (v *view) TakeSnapshot() *faster.Snapshot { return v.c.snapshot(v.instance, time.Now()) }
+func (v *view) ListTickers() map[string]*faster.History {
+	return map[string]*faster.History{v.history.Name: v.history}
+}
+func (v *view) ListAlerts() []faster.Alert                                 { return nil }
+func (v *view) LongRunning(threshold time.Duration) []faster.ActiveTracker { return nil }
+func (v *view) Track(key ...string) *faster.Tracker                        { return v.c.self.Track(key...) }
+
+// newView -- creates a view (and its History and Dashboard)
+func (c *Collector) newView(instance string) *view {
+	var rc = view{
+		c:        c,
+		instance: instance,
+		history:  faster.NewManualHistory("collector", c.interval, c.keep),
+	}
+	rc.dashboard = dashboard.NewForSource(&rc)
+	return &rc
+}
+
+// snapshot -- returns the latest Snapshot of the given instance (or the merged one if instance is empty)
+func (c *Collector) snapshot(instance string, now time.Time) *faster.Snapshot {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var rc *faster.Snapshot
+	if instance == "" {
+		// the totals of expired instances are kept (so the merged counts don't decrease)
+		var snapshots = make(faster.Snapshots, 0, len(c.instances)+1)
+		for _, i := range c.instances {
+			snapshots = append(snapshots, i.current())
+		}
+		if c.expired != nil {
+			snapshots = append(snapshots, c.expired)
+		}
+		rc = snapshots.Merge()
+	} else if i := c.instances[instance]; i != nil {
+		rc = i.current()
+	}
+
+	if rc == nil {
+		rc = &faster.Snapshot{TS: now}
+	}
+	return rc
+}
+
+// tick -- adds the current Snapshots to the merged and per-instance Histories (removing expired instances)
+func (c *Collector) tick(now time.Time) {
+	c.lock.Lock()
+	var views = make([]*view, 0, len(c.instances))
+	for name, i := range c.instances {
+		if c.expire > 0 && now.Sub(i.lastPush) > c.expire {
+			c.expired = faster.Snapshots{c.expired, i.current().Totals()}.Merge()
+			delete(c.instances, name)
+			continue
+		}
+		views = append(views, i.view)
+	}
+	c.lock.Unlock()
+
+	// all the Snapshots of a tick share the same timestamp (keeping the Histories aligned)
+	for _, v := range append(views, c.merged) {
+		var snap = *v.TakeSnapshot()
+		snap.TS = now
+		v.history.Push(&snap)
+	}
+}
+
+func (c *Collector) run() {
+	var ticker = time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			c.tick(now)
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// pushHandler -- implements POST /push
+func (c *Collector) pushHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed: "+r.Method, http.StatusMethodNotAllowed)
+		return
+	}
+	ref := c.self.Track("_collector", "push")
+	defer ref.Done()
+
+	var name, start = r.URL.Query().Get("instance"), r.URL.Query().Get("start")
+	if name == "" || strings.Contains(name, "/") {
+		http.Error(w, "missing or invalid 'instance' parameter", http.StatusBadRequest)
+		return
+	}
+
+	var data, err = ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxPushSize))
+	if err != nil {
+		http.Error(w, "failed to read snapshot: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var snap faster.Snapshot
+	if err = snap.UnmarshalBinary(data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.lock.Lock()
+	var i = c.instances[name]
+	if i == nil {
+		i = &instance{
+			name: name,
+			view: c.newView(name),
+		}
+		c.instances[name] = i
+	} else if start != i.start {
+		// the process was restarted -> keep the totals of its previous run (otherwise the instance's counts would decrease)
+		i.base = i.current().Totals()
+	}
+	i.snapshot = &snap
+	i.start = start
+	i.lastPush = time.Now()
+	c.lock.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// instancesHandler -- implements GET /instances/ and /instances/<name>/...
+func (c *Collector) instancesHandler(w http.ResponseWriter, r *http.Request) {
+	var rest = strings.TrimPrefix(r.URL.Path, "/instances/")
+	if rest == "" {
+		c.listInstances(w, r)
+		return
+	}
+
+	var name = rest
+	if pos := strings.Index(rest, "/"); pos >= 0 {
+		name = rest[:pos]
+	} else {
+		http.Redirect(w, r, name+"/", http.StatusFound)
+		return
+	}
+
+	c.lock.Lock()
+	var i = c.instances[name]
+	c.lock.Unlock()
+	if i == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	http.StripPrefix("/instances/"+name, i.view.dashboard).ServeHTTP(w, r)
+}
+
+var instancesTemplate = template.Must(template.New("instances").Parse(`<html>
+<head><title>go-faster collector</title>
+<style>
+body { font-family: monospace; }
+th, td { padding-left: 1em; text-align: left; }
+</style>
+</head>
+<body>
+<h1>go-faster collector</h1>
+<p><a href="../">merged view</a></p>
+<table>
+  <thead><tr><th>instance</th><th>last push</th></tr></thead>
+  <tbody>
+  {{range .}}
+    <tr><td><a href="{{.Name}}/">{{.Name}}</a></td><td title="{{.LastPush}}">{{.Age}} ago</td></tr>
+  {{end}}
+  </tbody>
+</table>
+</body>
+</html>
+`))
+
+func (c *Collector) listInstances(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed: "+r.Method, http.StatusMethodNotAllowed)
+		return
+	}
+	ref := c.self.Track("_collector", "instances")
+	defer ref.Done()
+
+	type instanceInfo struct {
+		Name     string
+		LastPush time.Time
+		Age      time.Duration
+	}
+
+	var now = time.Now()
+	var list []instanceInfo
+	c.lock.Lock()
+	for _, i := range c.instances {
+		list = append(list, instanceInfo{
+			Name:     i.name,
+			LastPush: i.lastPush,
+			Age:      now.Sub(i.lastPush).Truncate(time.Millisecond),
+		})
+	}
+	c.lock.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	if err := instancesTemplate.Execute(w, list); err != nil {
+		log.Print("Error: failed to render go-faster collector instance list: ", err.Error())
+	}
+}
+
+// Instances -- returns the names of all known instances (sorted)
+func (c *Collector) Instances() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var rc = make([]string, 0, len(c.instances))
+	for name := range c.instances {
+		rc = append(rc, name)
+	}
+	sort.Strings(rc)
+	return rc
+}
+
+// Snapshot -- returns the latest Snapshot of the given instance (or the merged Snapshot of all instances if name is empty)
+func (c *Collector) Snapshot(name string) *faster.Snapshot {
+	return c.snapshot(name, time.Now())
+}
+
+// ServeHTTP -- implements http.Handler
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mux.ServeHTTP(w, r)
+}
+
+// Close -- stops taking periodic History snapshots (calling it more than once is fine)
+func (c *Collector) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.self.Stop()
+	})
+}
+
+// Option -- configures a Collector (see New())
+type Option func(c *Collector)
+
+// WithExpire -- removes instances that haven't pushed any data for the given duration (default: 0, never)
+//
+// The totals of expired instances are kept in the merged view (i.e. an instance
+// that pushes again after expiring will be counted twice), so d should be well
+// above the Pushers' interval.
+func WithExpire(d time.Duration) Option {
+	return func(c *Collector) {
+		c.expire = d
+	}
+}
+
+// New -- returns a Collector keeping the given number of History snapshots (taken every interval)
+//
+// interval should match the Pushers' interval
+func New(interval time.Duration, keep int, opts ...Option) *Collector {
+	var rc = Collector{
+		interval:  interval,
+		keep:      keep,
+		self:      faster.New(faster.WithHistograms(false)),
+		mux:       http.NewServeMux(),
+		instances: make(map[string]*instance),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(&rc)
+	}
+	rc.merged = rc.newView("")
+
+	rc.mux.HandleFunc("/push", rc.pushHandler)
+	rc.mux.HandleFunc("/instances/", rc.instancesHandler)
+	rc.mux.Handle("/", rc.merged.dashboard)
+
+	go rc.run()
+	return &rc
+}
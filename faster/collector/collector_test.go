@@ -0,0 +1,229 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mreithub/go-faster/faster"
+	"github.com/mreithub/go-faster/faster/fastertest"
+	"github.com/stretchr/testify/assert"
+)
+
+// get -- performs a GET request (returning status code and body)
+func get(t *testing.T, url string) (int, string) {
+	var resp, err = http.Get(url)
+	if !assert.NoError(t, err) {
+		return 0, ""
+	}
+	defer resp.Body.Close()
+	var body, _ = ioutil.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestCollector(t *testing.T) {
+	var c = New(time.Hour, 10)
+	defer c.Close()
+	var srv = httptest.NewServer(c)
+	defer srv.Close()
+
+	var a, b = faster.New(), faster.New()
+	a.Track("http", "GET /").Done()
+	a.Track("http", "GET /a").Done()
+	b.Track("http", "GET /").Done()
+
+	var pushA, err = NewPusher(a, srv.URL+"/", "replica-a")
+	assert.NoError(t, err)
+	pushB, _ := NewPusher(b, srv.URL, "replica-b")
+	assert.NoError(t, pushA.Push(context.Background()))
+	assert.NoError(t, pushB.Push(context.Background()))
+	c.tick(time.Now())
+
+	assert.Equal(t, []string{"replica-a", "replica-b"}, c.Instances())
+	var merged = c.Snapshot("")
+	assert.Equal(t, int64(2), merged.Get("http", "GET /").Count())
+	assert.Equal(t, int64(1), merged.Get("http", "GET /a").Count())
+	assert.Nil(t, c.Snapshot("replica-b").Get("http", "GET /a"))
+	assert.Nil(t, c.Snapshot("unknown").Get("http"))
+
+	// newer pushes replace the instance's Snapshot
+	b.Track("http", "GET /").Done()
+	assert.NoError(t, pushB.Push(context.Background()))
+	c.tick(time.Now())
+	assert.Equal(t, int64(3), c.Snapshot("").Get("http", "GET /").Count())
+
+	// dashboards
+	var status, body = get(t, srv.URL+"/")
+	assert.Equal(t, 200, status)
+	assert.Contains(t, body, "GET /a")
+
+	status, body = get(t, srv.URL+"/instances/")
+	assert.Equal(t, 200, status)
+	assert.Contains(t, body, `href="replica-a/"`)
+	assert.Contains(t, body, `href="replica-b/"`)
+
+	status, body = get(t, srv.URL+"/instances/replica-b/")
+	assert.Equal(t, 200, status)
+	assert.Contains(t, body, "GET /")
+	assert.NotContains(t, body, "GET /a")
+
+	status, body = get(t, srv.URL+"/instances/replica-b") // -> redirect
+	assert.Equal(t, 200, status)
+	assert.Contains(t, body, "go-faster dashboard")
+
+	status, _ = get(t, srv.URL+"/instances/unknown/")
+	assert.Equal(t, 404, status)
+
+	// History (-> key page)
+	status, body = get(t, srv.URL+"/instances/replica-b/key/info.json?k=http&k=GET+%2F")
+	assert.Equal(t, 200, status)
+	var info struct {
+		Requests struct {
+			Counts []int64 `json:"counts"`
+		} `json:"requests"`
+		Total int64 `json:"total"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(body), &info))
+	assert.Equal(t, int64(2), info.Total)
+	assert.Equal(t, []int64{1}, info.Requests.Counts)
+}
+
+func TestPushErrors(t *testing.T) {
+	var c = New(time.Hour, 10)
+	defer c.Close()
+	var srv = httptest.NewServer(c)
+	defer srv.Close()
+
+	var p, _ = NewPusher(faster.New(faster.WithHistograms(false)), srv.URL, "a/b")
+	assert.Error(t, p.Push(context.Background()))
+
+	var resp, err = http.Post(srv.URL+"/push?instance=foo", "application/octet-stream", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = http.Get(srv.URL + "/push?instance=foo")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	resp.Body.Close()
+
+	assert.Empty(t, c.Instances())
+}
+
+func TestExpire(t *testing.T) {
+	var c = New(time.Hour, 10, WithExpire(time.Minute))
+	defer c.Close()
+	var srv = httptest.NewServer(c)
+	defer srv.Close()
+
+	var a, b = faster.New(faster.WithHistograms(false)), faster.New(faster.WithHistograms(false))
+	trackActive(a, "foo")
+	b.Track("foo").Done()
+	var pushA, _ = NewPusher(a, srv.URL, "a")
+	var pushB, _ = NewPusher(b, srv.URL, "b")
+	assert.NoError(t, pushA.Push(context.Background()))
+	assert.NoError(t, pushB.Push(context.Background()))
+	c.tick(time.Now())
+	assert.Equal(t, []string{"a", "b"}, c.Instances())
+
+	c.lock.Lock()
+	c.instances["a"].lastPush = time.Now().Add(-2 * time.Minute) // "a" stopped pushing
+	c.lock.Unlock()
+	c.tick(time.Now())
+	assert.Equal(t, []string{"b"}, c.Instances())
+
+	// the merged view keeps the totals of expired instances (but not their active calls)
+	var merged = c.Snapshot("")
+	assert.Equal(t, int64(3), merged.Get("foo").Count())
+	assert.Equal(t, int32(0), merged.Get("foo").Active())
+
+	c.tick(time.Now().Add(3 * time.Minute))
+	assert.Empty(t, c.Instances())
+	assert.Equal(t, int64(3), c.Snapshot("").Get("foo").Count())
+}
+
+// trackActive -- tracks two calls of the given key (keeping a third one active)
+func trackActive(f *faster.Faster, key ...string) {
+	f.Track(key...).Done()
+	f.Track(key...).Done()
+	f.Track(key...)
+}
+
+func TestRestart(t *testing.T) {
+	var c = New(time.Hour, 10)
+	defer c.Close()
+	var srv = httptest.NewServer(c)
+	defer srv.Close()
+
+	var f = faster.New(faster.WithHistograms(false))
+	trackActive(f, "foo")
+	var p, _ = NewPusher(f, srv.URL, "a")
+	assert.NoError(t, p.Push(context.Background()))
+	c.tick(time.Now())
+
+	// a restarted process starts counting from zero again -> the instance's counts keep growing
+	f = faster.New(faster.WithHistograms(false))
+	f.Track("foo").Done()
+	p, _ = NewPusher(f, srv.URL, "a")
+	assert.NoError(t, p.Push(context.Background()))
+	c.tick(time.Now())
+
+	for _, name := range []string{"a", ""} {
+		var snap = c.Snapshot(name)
+		assert.Equal(t, int64(3), snap.Get("foo").Count(), name)
+		assert.Equal(t, int32(0), snap.Get("foo").Active(), name)
+	}
+}
+
+func TestEvictedKeys(t *testing.T) {
+	var c = New(time.Hour, 10)
+	defer c.Close()
+	var srv = httptest.NewServer(c)
+	defer srv.Close()
+
+	var f, clock = fastertest.NewWithClock(t, faster.WithHistograms(false))
+	f.SetEvictAfter(time.Minute)
+	f.Track("idle").Done()
+	f.Track("busy").Done()
+	var p, _ = NewPusher(f, srv.URL, "a")
+	assert.NoError(t, p.Push(context.Background()))
+
+	// "idle" gets evicted by the pushing process (which doesn't mean it was restarted)
+	for i := 0; i < 4; i++ {
+		clock.Advance(30 * time.Second)
+		f.Track("busy").Done()
+	}
+	assert.Nil(t, f.TakeSnapshot().Get("idle"))
+	assert.NoError(t, p.Push(context.Background()))
+
+	for _, name := range []string{"a", ""} {
+		var snap = c.Snapshot(name)
+		assert.Equal(t, int64(5), snap.Get("busy").Count(), name)
+		assert.Nil(t, snap.Get("idle"), name)
+	}
+}
+
+func TestPusherStart(t *testing.T) {
+	var c = New(time.Hour, 10)
+	defer c.Close()
+	var srv = httptest.NewServer(c)
+	defer srv.Close()
+
+	var f = faster.New(faster.WithHistograms(false))
+	f.Track("foo").Done()
+	var p, _ = NewPusher(f, srv.URL, "a")
+	p.Start(10 * time.Millisecond)
+	p.Start(10 * time.Millisecond) // no effect (a second goroutine couldn't be stopped)
+
+	assert.Eventually(t, func() bool {
+		return c.Snapshot("a").Get("foo") != nil
+	}, time.Second, 10*time.Millisecond)
+
+	p.Stop()
+	p.Stop()
+	c.Close() // closing twice is fine
+}
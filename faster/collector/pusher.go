@@ -0,0 +1,129 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mreithub/go-faster/faster"
+)
+
+// Pusher -- periodically sends Snapshots of a Faster instance to a Collector
+type Pusher struct {
+	// Client -- used to send the Snapshots (http.DefaultClient if nil)
+	Client *http.Client
+
+	faster   *faster.Faster
+	url      string
+	instance string
+
+	// guards done
+	lock sync.Mutex
+	done chan struct{}
+}
+
+// Push -- sends a single Snapshot to the Collector
+func (p *Pusher) Push(ctx context.Context) error {
+	var data, err = p.faster.TakeSnapshot().MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequest("POST", p.url, bytes.NewReader(data)); err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-type", "application/octet-stream")
+
+	var client = p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var resp *http.Response
+	if resp, err = client.Do(req); err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector: push failed: %s", resp.Status)
+	}
+	return nil
+}
+
+// Start -- pushes a Snapshot every interval (in a separate goroutine, until Stop() is called)
+//
+// Errors will be logged. Calling Start() again before Stop() has no effect.
+func (p *Pusher) Start(interval time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.done != nil {
+		return // already running
+	}
+
+	p.done = make(chan struct{})
+	go func(done chan struct{}) {
+		var ticker = time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				var ctx, cancel = context.WithTimeout(context.Background(), interval)
+				if err := p.Push(ctx); err != nil {
+					log.Print("go-faster: ", err)
+				}
+				cancel()
+			case <-done:
+				return
+			}
+		}
+	}(p.done)
+}
+
+// Stop -- stops pushing Snapshots (see Start())
+func (p *Pusher) Stop() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.done != nil {
+		close(p.done)
+		p.done = nil
+	}
+}
+
+// NewPusher -- returns a Pusher sending Snapshots of f to the Collector at collectorURL
+//
+// instance identifies this process in the collector (defaults to the hostname if empty). The
+// Snapshots are sent along with f.StartTS (allowing the Collector to detect restarts).
+func NewPusher(f *faster.Faster, collectorURL string, instance string) (*Pusher, error) {
+	var base, err = url.Parse(collectorURL)
+	if err != nil {
+		return nil, err
+	}
+	if instance == "" {
+		if instance, err = os.Hostname(); err != nil {
+			return nil, err
+		}
+	}
+
+	var pushURL = base.ResolveReference(&url.URL{
+		Path: "push",
+		RawQuery: url.Values{
+			"instance": {instance},
+			"start":    {strconv.FormatInt(f.StartTS.UnixNano(), 10)},
+		}.Encode(),
+	})
+	return &Pusher{
+		faster:   f,
+		url:      pushURL.String(),
+		instance: instance,
+	}, nil
+}
@@ -0,0 +1,200 @@
+package faster
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// comparePercentiles -- percentiles compared by Compare() (see KeyDiff.OldPercentiles)
+var comparePercentiles = []int{50, 90, 99}
+
+// KeyDiff -- the changes of a single key between two Snapshots (see Compare())
+type KeyDiff struct {
+	Path []string
+	// Old, New -- the key's data in both Snapshots (nil if it's missing in one of them)
+	Old, New DataPoint
+
+	// OldPercentiles, NewPercentiles -- p50, p90 and p99 (nil if there's no histogram for the key)
+	OldPercentiles, NewPercentiles []time.Duration
+	// P -- p-value of a Mann-Whitney U test comparing both histograms (NaN if one of them is missing)
+	//
+	// Low values indicate that the durations of Old and New really differ (i.e.
+	// that the change is unlikely to be noise). Histogram buckets are powers of
+	// two, so the test is rather conservative (smaller changes won't be significant)
+	P float64
+	// OldN, NewN -- number of values the test was based on
+	OldN, NewN int64
+}
+
+// delta -- returns the relative change from a to b (in percent, NaN if a is 0)
+func delta(a, b float64) float64 {
+	if a == 0 {
+		return math.NaN()
+	}
+	return 100 * (b - a) / a
+}
+
+// CountDelta -- returns the relative change of Count() (in percent, NaN if unknown)
+func (d *KeyDiff) CountDelta() float64 {
+	if d.Old == nil || d.New == nil {
+		return math.NaN()
+	}
+	return delta(float64(d.Old.Count()), float64(d.New.Count()))
+}
+
+// AverageDelta -- returns the relative change of Average() (in percent, NaN if unknown)
+func (d *KeyDiff) AverageDelta() float64 {
+	if d.Old == nil || d.New == nil || d.Old.Count() == 0 || d.New.Count() == 0 {
+		return math.NaN()
+	}
+	return delta(float64(d.Old.Average()), float64(d.New.Average()))
+}
+
+// PercentileDelta -- returns the relative change of the given percentile (50, 90 or 99 - in percent, NaN if unknown)
+func (d *KeyDiff) PercentileDelta(percentile int) float64 {
+	for i, p := range comparePercentiles {
+		if p == percentile && d.OldPercentiles != nil && d.NewPercentiles != nil {
+			return delta(float64(d.OldPercentiles[i]), float64(d.NewPercentiles[i]))
+		}
+	}
+	return math.NaN()
+}
+
+// Significant -- returns true if P is below alpha (e.g. 0.05)
+func (d *KeyDiff) Significant(alpha float64) bool {
+	return d.P < alpha
+}
+
+// Thresholds -- regression limits used by KeyDiff.Regressions()
+//
+// Limits are relative increases in percent (0 disables the check)
+type Thresholds struct {
+	Average float64
+	P90     float64
+	P99     float64
+
+	// Alpha -- if set, changes only count as regressions if they're significant
+	// at that level (only applies to keys with histograms)
+	Alpha float64
+	// MinCount -- keys with fewer calls (in either Snapshot) are ignored
+	MinCount int64
+}
+
+// Regressions -- returns descriptions of the thresholds the key exceeded (nil if none)
+func (d *KeyDiff) Regressions(t Thresholds) []string {
+	if d.Old == nil || d.New == nil || d.Old.Count() < t.MinCount || d.New.Count() < t.MinCount {
+		return nil
+	}
+	if t.Alpha > 0 && !math.IsNaN(d.P) && !d.Significant(t.Alpha) {
+		return nil
+	}
+
+	var rc []string
+	var check = func(name string, change, limit float64) {
+		if limit > 0 && change > limit {
+			rc = append(rc, fmt.Sprintf("%s %+.2f%% (limit: %+.2f%%)", name, change, limit))
+		}
+	}
+	check("avg", d.AverageDelta(), t.Average)
+	check("p90", d.PercentileDelta(90), t.P90)
+	check("p99", d.PercentileDelta(99), t.P99)
+	return rc
+}
+
+// Compare -- returns the changes of all keys between two Snapshots (sorted by path)
+//
+// The Snapshots may come from different Faster instances or processes (keys are
+// matched by path, like in Snapshot.Sub()). Keys without calls in both Snapshots
+// are skipped.
+func Compare(older, newer *Snapshot) []KeyDiff {
+	var diffs = map[string]*KeyDiff{}
+	var add = func(s *Snapshot, isNew bool) {
+		s.walk(func(path []string) {
+			var d = s.getData(path)
+			if d == nil || d.count == 0 {
+				return
+			}
+
+			var key = pathKey(path)
+			var diff = diffs[key]
+			if diff == nil {
+				diff = &KeyDiff{Path: append([]string(nil), path...), P: math.NaN()}
+				diffs[key] = diff
+			}
+			var copied = *d
+			if isNew {
+				diff.New = &copied
+			} else {
+				diff.Old = &copied
+			}
+		})
+	}
+	add(older, false)
+	add(newer, true)
+
+	var rc = make([]KeyDiff, 0, len(diffs))
+	for _, diff := range diffs {
+		var oldHist, newHist = older.getHistogram(diff.Path), newer.getHistogram(diff.Path)
+		if oldHist != nil && oldHist.count > 0 {
+			diff.OldPercentiles = oldHist.GetPercentiles(comparePercentiles...)
+		}
+		if newHist != nil && newHist.count > 0 {
+			diff.NewPercentiles = newHist.GetPercentiles(comparePercentiles...)
+		}
+		if diff.OldPercentiles != nil && diff.NewPercentiles != nil {
+			diff.P, diff.OldN, diff.NewN = mannWhitneyU(oldHist, newHist)
+		}
+		rc = append(rc, *diff)
+	}
+
+	sort.Slice(rc, func(i, j int) bool {
+		return pathKey(rc[i].Path) < pathKey(rc[j].Path)
+	})
+	return rc
+}
+
+// mannWhitneyU -- returns the two-sided p-value of a Mann-Whitney U test comparing
+// both histograms' values (and the sample sizes)
+//
+// Values in the same bucket are treated as ties. Uses the normal approximation
+// (with tie and continuity correction), which is fine for the sample sizes we
+// usually deal with.
+func mannWhitneyU(a, b *Histogram) (float64, int64, int64) {
+	var n1, n2 float64
+	for i := range a.buckets {
+		n1 += float64(a.buckets[i])
+		n2 += float64(b.buckets[i])
+	}
+	if n1 == 0 || n2 == 0 {
+		return math.NaN(), int64(n1), int64(n2)
+	}
+
+	// rank sum of a (and tie correction)
+	var rank, rankSum, ties float64
+	for i := range a.buckets {
+		var t = float64(a.buckets[i]) + float64(b.buckets[i])
+		if t == 0 {
+			continue
+		}
+		rankSum += float64(a.buckets[i]) * (rank + (t+1)/2)
+		ties += t*t*t - t
+		rank += t
+	}
+
+	var n = n1 + n2
+	var u = rankSum - n1*(n1+1)/2
+	var mean = n1 * n2 / 2
+	var variance = n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1)))
+	if variance <= 0 {
+		// all values are in the same bucket
+		return 1, int64(n1), int64(n2)
+	}
+
+	var z = (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		z = 0
+	}
+	return math.Erfc(z / math.Sqrt2), int64(n1), int64(n2)
+}
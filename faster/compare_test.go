@@ -0,0 +1,83 @@
+package faster
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	var a, b = New(), New()
+	trackN(a, 100, time.Millisecond, "http", "GET /")
+	trackN(b, 80, time.Millisecond, "http", "GET /")
+	trackN(b, 40, 4*time.Millisecond, "http", "GET /")
+	trackN(a, 50, time.Millisecond, "http", "POST /")
+	trackN(b, 50, time.Millisecond, "http", "POST /")
+	trackN(a, 1, time.Second, "removed")
+	trackN(b, 1, time.Second, "added")
+
+	var diffs = Compare(a.TakeSnapshot(), b.TakeSnapshot())
+	if !assert.Len(t, diffs, 4) {
+		return
+	}
+	assert.Equal(t, []string{"added"}, diffs[0].Path)
+	assert.Nil(t, diffs[0].Old)
+	assert.True(t, math.IsNaN(diffs[0].CountDelta()))
+	assert.True(t, math.IsNaN(diffs[0].P))
+	assert.Nil(t, diffs[0].Regressions(Thresholds{Average: 1}))
+
+	var get = diffs[1]
+	assert.Equal(t, []string{"http", "GET /"}, get.Path)
+	assert.Equal(t, 20.0, get.CountDelta())
+	assert.InDelta(t, 100, get.AverageDelta(), 0.01)
+	assert.Equal(t, 300.0, get.PercentileDelta(99))
+	assert.Equal(t, 0.0, get.PercentileDelta(50))
+	assert.True(t, math.IsNaN(get.PercentileDelta(75)))
+	assert.Equal(t, int64(100), get.OldN)
+	assert.Equal(t, int64(120), get.NewN)
+	assert.True(t, get.Significant(0.001))
+
+	assert.Len(t, get.Regressions(Thresholds{Average: 50, P99: 100, Alpha: 0.05}), 2)
+	assert.Len(t, get.Regressions(Thresholds{Average: 200, P90: 100}), 1)
+	assert.Nil(t, get.Regressions(Thresholds{Average: 200, P99: 500}))
+	assert.Nil(t, get.Regressions(Thresholds{Average: 50, MinCount: 101}))
+
+	var post = diffs[2]
+	assert.Equal(t, []string{"http", "POST /"}, post.Path)
+	assert.Equal(t, 0.0, post.AverageDelta())
+	assert.Equal(t, 1.0, post.P)
+	assert.False(t, post.Significant(0.05))
+
+	assert.Equal(t, []string{"removed"}, diffs[3].Path)
+	assert.Nil(t, diffs[3].New)
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	var a, b Histogram
+	for i := 0; i < 20; i++ {
+		a.Add(time.Millisecond)
+		a.Add(2 * time.Millisecond)
+		b.Add(time.Millisecond)
+		b.Add(2 * time.Millisecond)
+	}
+	var p, n1, n2 = mannWhitneyU(&a, &b)
+	assert.InDelta(t, 1, p, 0.0001)
+	assert.Equal(t, int64(40), n1)
+	assert.Equal(t, int64(40), n2)
+
+	// slightly slower - not significant with that few values
+	b.Add(4 * time.Millisecond)
+	p, _, _ = mannWhitneyU(&a, &b)
+	assert.True(t, p > 0.05)
+
+	for i := 0; i < 20; i++ {
+		b.Add(4 * time.Millisecond)
+	}
+	p, _, _ = mannWhitneyU(&a, &b)
+	assert.True(t, p < 0.01)
+
+	p, _, _ = mannWhitneyU(&a, &Histogram{})
+	assert.True(t, math.IsNaN(p))
+}
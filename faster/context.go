@@ -0,0 +1,57 @@
+package faster
+
+import (
+	"context"
+)
+
+// trackerKey -- context.Context key type for Tracker values
+type trackerKey struct{}
+
+// WithTracker -- returns a copy of ctx that carries the given Tracker (retrieve it using FromContext())
+//
+// A nil ctx is treated like context.Background()
+func WithTracker(ctx context.Context, t *Tracker) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, trackerKey{}, t)
+}
+
+// FromContext -- returns the Tracker stored in ctx (or nil if there is none)
+func FromContext(ctx context.Context) *Tracker {
+	if ctx == nil {
+		return nil
+	}
+	var rc, _ = ctx.Value(trackerKey{}).(*Tracker)
+	return rc
+}
+
+// StartSpan -- tracks 'key' nested below the Tracker stored in ctx (and returns a context carrying the new Tracker)
+//
+// Uses the Faster instance of ctx's Tracker while it's still active (the Singleton otherwise).
+// If ctx doesn't contain a Tracker, 'key' will be tracked at the root. ctx may be nil.
+func StartSpan(ctx context.Context, key ...string) (context.Context, *Tracker) {
+	var f = Singleton
+	if parent := FromContext(ctx); parent != nil && parent.parent != nil {
+		f = parent.parent
+	}
+	return f.StartSpan(ctx, key...)
+}
+
+// StartSpan -- tracks 'key' nested below the Tracker stored in ctx (and returns a context carrying the new Tracker)
+//
+// The returned Tracker will always be tracked by this Faster instance (even if ctx's
+// Tracker belongs to a different one - only its path will be used as prefix). Finished
+// Trackers are used as prefix as well (e.g. for work outliving the request that started it).
+// If ctx doesn't contain a Tracker, 'key' will be tracked at the root. ctx may be nil.
+func (f *Faster) StartSpan(ctx context.Context, key ...string) (context.Context, *Tracker) {
+	var path = key
+	if parent := FromContext(ctx); parent != nil {
+		path = make([]string, 0, len(parent.path)+len(key))
+		path = append(path, parent.path...)
+		path = append(path, key...)
+	}
+
+	var rc = f.Track(path...)
+	return WithTracker(ctx, rc), rc
+}
@@ -0,0 +1,76 @@
+package faster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	f := New()
+
+	var ctx = context.Background()
+	assert.Nil(t, FromContext(ctx))
+
+	ctx, outer := f.StartSpan(ctx, "http", "GET /")
+	assert.Equal(t, outer, FromContext(ctx))
+	assert.Equal(t, []string{"http", "GET /"}, outer.Path())
+
+	innerCtx, inner := f.StartSpan(ctx, "dao", "psql")
+	assert.Equal(t, []string{"http", "GET /", "dao", "psql"}, inner.Path())
+	assert.Equal(t, inner, FromContext(innerCtx))
+	assert.Equal(t, outer, FromContext(ctx))
+
+	// the package level StartSpan() should use the Faster instance of ctx's Tracker
+	_, other := StartSpan(ctx, "cache")
+	assert.Equal(t, f, other.parent)
+
+	other.Done()
+	inner.Done()
+	outer.Done()
+
+	snap := f.TakeSnapshot()
+	assert.Equal(t, int64(1), snap.Get("http", "GET /").Count())
+	assert.Equal(t, int64(1), snap.Get("http", "GET /", "dao", "psql").Count())
+	assert.Equal(t, int64(1), snap.Get("http", "GET /", "cache").Count())
+	assert.Equal(t, int32(0), snap.Get("http", "GET /").Active())
+
+	// finished Trackers still provide the path prefix
+	_, late := f.StartSpan(ctx, "late")
+	assert.Equal(t, []string{"http", "GET /", "late"}, late.Path())
+	late.Done()
+
+	// nil contexts
+	nilCtx, root := f.StartSpan(nil, "root")
+	assert.Equal(t, []string{"root"}, root.Path())
+	assert.Equal(t, root, FromContext(nilCtx))
+	root.Done()
+	assert.NotNil(t, WithTracker(nil, nil))
+}
+
+func TestWatchContext(t *testing.T) {
+	f := New()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.Track("ok").WatchContext(ctx).Done()
+	cancel()
+	f.Track("canceled").WatchContext(ctx).Done()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-ctx.Done()
+	f.Track("timeout").WatchContext(ctx).Done()
+
+	snap := f.TakeSnapshot()
+	assert.Equal(t, int64(1), snap.Get("ok").Count())
+	assert.Empty(t, snap.Children("ok"))
+
+	assert.Equal(t, int64(1), snap.Get("canceled").Count())
+	assert.Equal(t, []string{"_canceled"}, snap.Children("canceled"))
+	assert.Equal(t, int64(1), snap.Get("canceled", "_canceled").Count())
+	assert.Equal(t, int32(0), snap.Get("canceled", "_canceled").Active())
+
+	assert.Equal(t, []string{"_deadlineExceeded"}, snap.Children("timeout"))
+	assert.Equal(t, int64(1), snap.Get("timeout", "_deadlineExceeded").Count())
+}
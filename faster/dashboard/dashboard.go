@@ -12,9 +12,21 @@ import (
 	"github.com/mreithub/go-faster/faster"
 )
 
+// Source -- data source of a Dashboard (implemented by *faster.Faster)
+type Source interface {
+	TakeSnapshot() *faster.Snapshot
+	ListTickers() map[string]*faster.History
+	ListAlerts() []faster.Alert
+	LongRunning(threshold time.Duration) []faster.ActiveTracker
+
+	// Track -- used to track the Dashboard's own requests (as "_faster", <page>)
+	Track(key ...string) *faster.Tracker
+}
+
 // Dashboard -- implements go-faster's web dashboard
 type Dashboard struct {
-	faster    *faster.Faster
+	faster    Source
+	startTS   time.Time
 	mux       *http.ServeMux
 	templates map[string]*template.Template
 	keyPage   *keyPage
@@ -42,17 +54,29 @@ func (d *Dashboard) indexPage(w http.ResponseWriter, r *http.Request) {
 	defer ref.Done()
 
 	var tpl = d.templates["index.html"]
-	var data = flattenSnapshot(d.faster.TakeSnapshot())
+	var snap = d.faster.TakeSnapshot()
+	var data = flattenSnapshot(snap)
 	sortByPath(data)
 
+	var firing = 0
+	for _, alert := range d.faster.ListAlerts() {
+		if alert.Firing {
+			firing++
+		}
+	}
+
 	var hostname, _ = os.Hostname()
 	var err = tpl.Execute(w, map[string]interface{}{
 		"data":       data,
+		"firing":     firing,
+		"dropped":    snap.DroppedKeys(),
+		"droppedEvs": snap.DroppedEvents(),
+		"runtime":    snap.Get("_runtime", "goroutines") != nil,
 		"cores":      runtime.NumCPU(),
 		"goroutines": runtime.NumGoroutine(),
 		"hostname":   hostname,
-		"uptime":     time.Now().Sub(d.faster.StartTS),
-		"startTS":    d.faster.StartTS.Format(time.RFC3339),
+		"uptime":     time.Now().Sub(d.startTS),
+		"startTS":    d.startTS.Format(time.RFC3339),
 	})
 
 	if err != nil {
@@ -60,6 +84,50 @@ func (d *Dashboard) indexPage(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (d *Dashboard) alertsPage(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, "GET") {
+		return
+	}
+	ref := d.faster.Track("_faster", "alerts")
+	defer ref.Done()
+
+	var tpl = d.templates["alerts.html"]
+	var err = tpl.Execute(w, map[string]interface{}{
+		"alerts": d.faster.ListAlerts(),
+	})
+
+	if err != nil {
+		log.Print("Error: failed to render go-faster alerts.html template: ", err.Error())
+	}
+}
+
+func (d *Dashboard) longRunningPage(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, "GET") {
+		return
+	}
+	ref := d.faster.Track("_faster", "longRunning")
+	defer ref.Done()
+
+	var threshold = 10 * time.Second
+	if param := r.URL.Query().Get("threshold"); param != "" {
+		var err error
+		if threshold, err = time.ParseDuration(param); err != nil {
+			http.Error(w, "invalid 'threshold' parameter: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var tpl = d.templates["longRunning.html"]
+	var err = tpl.Execute(w, map[string]interface{}{
+		"threshold": threshold,
+		"trackers":  d.faster.LongRunning(threshold),
+	})
+
+	if err != nil {
+		log.Print("Error: failed to render go-faster longRunning.html template: ", err.Error())
+	}
+}
+
 func (d *Dashboard) snapshotJSON(w http.ResponseWriter, r *http.Request) {
 	if !checkMethod(w, r, "GET") {
 		return
@@ -79,18 +147,26 @@ func (d *Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 // New -- returns a HTTP Dashboard for the given Faster instance
-func New(faster *faster.Faster) *Dashboard {
+func New(f *faster.Faster) *Dashboard {
+	var rc = NewForSource(f)
+	rc.startTS = f.StartTS
+	return rc
+}
+
+// NewForSource -- returns a HTTP Dashboard for the given Source (e.g. snapshots collected from other processes)
+func NewForSource(source Source) *Dashboard {
 	var mux = http.NewServeMux()
 	var templates, err = parseTemplates()
 	if err != nil {
 		panic(err) // this only happens if there are template parsing errors
 	}
 	var rc = Dashboard{
-		faster:    faster,
+		faster:    source,
+		startTS:   time.Now(),
 		mux:       mux,
 		templates: templates,
 		keyPage: &keyPage{
-			faster:    faster,
+			faster:    source,
 			templates: templates,
 		},
 	}
@@ -98,6 +174,8 @@ func New(faster *faster.Faster) *Dashboard {
 	mux.HandleFunc("/", rc.indexPage)
 	mux.Handle("/key", rc.keyPage)
 	mux.HandleFunc("/key/info.json", rc.keyPage.InfoJSON)
+	mux.HandleFunc("/alerts", rc.alertsPage)
+	mux.HandleFunc("/longRunning", rc.longRunningPage)
 	mux.HandleFunc("/snapshot.json", rc.snapshotJSON)
 
 	return &rc
@@ -0,0 +1,95 @@
+package dashboard
+
+import (
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mreithub/go-faster/faster"
+	"github.com/mreithub/go-faster/faster/fastertest"
+	"github.com/stretchr/testify/assert"
+)
+
+// get -- performs a request against the Dashboard (returning status code and body)
+func get(d *Dashboard, method, url string) (int, string) {
+	var w = httptest.NewRecorder()
+	d.ServeHTTP(w, httptest.NewRequest(method, url, nil))
+	return w.Code, w.Body.String()
+}
+
+func TestAlertsPage(t *testing.T) {
+	var f, clock = fastertest.NewWithClock(t)
+	var d = New(f)
+
+	var status, body = get(d, "GET", "/alerts")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "no alert rules defined")
+
+	f.SetTicker("1sec", time.Second, 10)
+	var rule = faster.AlertRule{Name: "busy", Path: []string{"http", "GET /"}, Ticker: "1sec", Metric: faster.Rate(), Threshold: 2}
+	f.SetAlert(rule, nil)
+	f.SetAlert(faster.AlertRule{Name: "cron", Path: []string{"cron"}, Ticker: "1sec", Metric: faster.Rate(), Threshold: 0.5, For: 5}, nil)
+	for i := 0; i < 3; i++ {
+		f.Track("http", "GET /").Done()
+		f.Track("http", "GET /").Done()
+		f.Track("http", "GET /").Done()
+		f.Track("cron").Done()
+		clock.Advance(time.Second)
+	}
+
+	status, body = get(d, "GET", "/alerts")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "<td>busy</td>")
+	assert.Contains(t, body, html.EscapeString(rule.String()))
+	assert.Contains(t, body, `<td class="firing">firing</td>`)
+	assert.Contains(t, body, `<td class="pending" title="violated for 3 of 5 ticks">pending</td>`)
+	assert.Contains(t, body, "<td>3.00/s</td>")
+	assert.Contains(t, body, `href="key?k=http&amp;k=GET&#43;%2F"`)
+
+	// the index page counts the firing alerts
+	status, body = get(d, "GET", "/")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "1 firing")
+
+	status, _ = get(d, "POST", "/alerts")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+}
+
+func TestLongRunningPage(t *testing.T) {
+	var f, clock = fastertest.NewWithClock(t)
+	var d = New(f)
+
+	var leaked = f.Track("jobs", "leaked")
+	clock.Advance(time.Minute)
+	f.SetCaptureStacks(true)
+	var withStack = f.Track("jobs", "withStack")
+	clock.Advance(20 * time.Second)
+	f.Track("jobs", "young") // below the default threshold (10s)
+
+	var status, body = get(d, "GET", "/longRunning")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "jobs | leaked")
+	assert.Contains(t, body, "<td>1m20s</td>")
+	assert.Contains(t, body, "jobs | withStack")
+	assert.Contains(t, body, "dashboard_test.go:")
+	assert.Contains(t, body, `<span title="stack capturing is disabled">-</span>`)
+	assert.NotContains(t, body, "jobs | young")
+
+	status, body = get(d, "GET", "/longRunning?threshold=1m")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "jobs | leaked")
+	assert.NotContains(t, body, "jobs | withStack")
+
+	leaked.Done()
+	withStack.Done()
+	status, body = get(d, "GET", "/longRunning?threshold=1h")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "no trackers active for more than 1h0m0s")
+
+	status, _ = get(d, "GET", "/longRunning?threshold=soon")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = get(d, "POST", "/longRunning")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+}
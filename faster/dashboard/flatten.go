@@ -27,7 +27,7 @@ func (e *flatEntry) JSONPath() string {
 }
 
 // formats a time.Duration as string (in msec) that can be easily parsed by the human eye when aligned right
-func (e *flatEntry) toMsec(value time.Duration) string {
+func toMsec(value time.Duration) string {
 	if value == 0 {
 		return ""
 	}
@@ -49,11 +49,21 @@ func (e *flatEntry) toMsec(value time.Duration) string {
 
 // PrettyAverage -- returns the average in msec (with space as thousands-separator)
 func (e *flatEntry) PrettyAverage() string {
-	return e.toMsec(e.Data.Average())
+	return toMsec(e.Data.Average())
 }
 
 func (e *flatEntry) PrettyTotal() string {
-	return e.toMsec(e.Data.TotalTime())
+	return toMsec(e.Data.TotalTime())
+}
+
+// Value -- returns the key's Counter or Gauge value (or "" if it has neither)
+func (e *flatEntry) Value() string {
+	if gauge, ok := e.Data.Gauge(); ok {
+		return strconv.FormatFloat(gauge, 'f', -1, 64)
+	} else if e.Data.HasCounter() {
+		return strconv.FormatInt(e.Data.Counter(), 10)
+	}
+	return ""
 }
 
 // flattenSnapshot -- takes the hierarchical data stored in a faster.Snapshot and puts it into a (sorted) slice
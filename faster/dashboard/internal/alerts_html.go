@@ -0,0 +1,57 @@
+package internal
+
+// AlertsHTML -- dashboard template listing the state of all AlertRules
+var AlertsHTML = `
+<html>
+<head><title>alerts :: go-faster dashboard</title>
+<style>
+body {
+  font-family: monospace;
+}
+
+th, td { padding-left: 1em; text-align: left; }
+tr:hover { background-color: rgba(192,224,255,.5);}
+.firing { color: #c00; font-weight: bold; }
+.pending { color: #c80; }
+</style>
+</head>
+<body>
+<h2>go-faster: alerts</h2>
+
+<a href="./">Back</a>
+
+{{if .alerts}}
+<table>
+  <thead><tr>
+    <th>Name</th>
+    <th>Rule</th>
+    <th>State</th>
+    <th title="time of the last state transition">since</th>
+    <th title="the metric's latest value">value</th>
+    <th>last evaluated</th>
+  </tr></thead>
+  <tbody>
+    {{range .alerts}}
+    <tr>
+      <td>{{.Rule.Name}}</td>
+      <td><a href="{{keyLink .Rule.Path}}">{{.Rule}}</a></td>
+      {{if .Firing}}
+      <td class="firing">firing</td>
+      {{else if .Violations}}
+      <td class="pending" title="violated for {{.Violations}} of {{.Rule.For}} ticks">pending</td>
+      {{else}}
+      <td>ok</td>
+      {{end}}
+      <td>{{if not .Since.IsZero}}{{.Since.Format "2006-01-02 15:04:05"}}{{end}}</td>
+      <td>{{if .HasValue}}{{.Rule.Metric.Format .Value}}{{else}}-{{end}}</td>
+      <td>{{if not .LastEval.IsZero}}{{.LastEval.Format "2006-01-02 15:04:05"}}{{end}}</td>
+    </tr>
+    {{end}}
+  </tbody>
+</table>
+{{else}}
+<p>:: no alert rules defined (see Faster.SetAlert()) ::</p>
+{{end}}
+</body>
+</html>
+`
@@ -26,6 +26,17 @@ td:first-child { text-align: initial; }
 <tr><th>app uptime</th><td title="{{.startTS}}">{{.uptime}}</td></tr>
 <tr><th>cpu</th><td>{{.cores}} cores</td></tr>
 <tr><th>goroutines</th><td>{{.goroutines}}</td></tr>
+{{if .runtime}}
+<tr title="Go runtime metrics (see Faster.SetRuntimeMetrics())"><th>runtime</th><td><a href="{{keyLink (list "_runtime" "gc" "pauses")}}">GC pauses</a>, <a href="{{keyLink (list "_runtime" "sched" "latency")}}">scheduler latency</a>, <a href="{{keyLink (list "_runtime" "goroutines")}}">goroutines</a></td></tr>
+{{end}}
+<tr><th>trackers</th><td><a href="longRunning">long running</a></td></tr>
+<tr><th>alerts</th><td><a href="alerts">{{if .firing}}<b style="color: #c00">{{.firing}} firing</b>{{else}}none firing{{end}}</a></td></tr>
+{{with .droppedEvs}}{{if .Total}}
+<tr title="tracking events dropped because the event buffer was full (counts are too low)"><th>dropped events</th><td><b style="color: #c00">{{.Track}} Track(), {{.Done}} Done()</b></td></tr>
+{{end}}{{end}}
+{{range .dropped}}
+<tr title="distinct keys that didn't get their own entry because of a key limit"><th>dropped keys</th><td><a href="{{keyLink .Path}}">{{range $i, $name := .Path}}{{if $i}} | {{end}}{{$name}}{{end}}</a>: {{.Distinct}}</td></tr>
+{{end}}
 </tbody></table>
 
 
@@ -37,12 +48,13 @@ td:first-child { text-align: initial; }
     <th title="number of finished instances">count</th>
     <th title="total time spent">total ms</th>
     <th title="average time spent">average ms</th>
+    <th title="Counter or Gauge value">value</th>
   </tr></thead>
   <tbody>
     {{range .data}}
     <tr data-path="{{.JSONPath}}">
       <td>{{range .Path}}&nbsp;&nbsp;{{end -}}
-        {{if gt .Data.Count 0 }}
+        {{if or (gt .Data.Count 0) .Value}}
           <a href="{{keyLink .Key}}">{{.Name}}</a>
         {{else}}
           {{.Name}}
@@ -52,6 +64,7 @@ td:first-child { text-align: initial; }
       <td>{{or .Data.Count ""}}</td>
       <td data-raw="{{printf "%d" .Data.TotalTime}}" title="{{.Data.TotalTime}}">{{.PrettyTotal}}</td>
       <td data-raw="{{printf "%d" .Data.Average}}" title="{{.Data.Average}}">{{.PrettyAverage}}</td>
+      <td>{{.Value}}</td>
     </tr>
     {{end}}
   </tbody>
@@ -14,6 +14,9 @@ var KeyHTML = `
 body {
   font-family: monospace;
 }
+
+th, td { padding-left: 1em; text-align: left; }
+tr:hover { background-color: rgba(192,224,255,.5);}
 </style>
 </head>
 <body>
@@ -38,9 +41,26 @@ body {
 
 <div id="chart" style="width: 100%; min-height: 300px;"></div>
 
+<div id="allocations" style="display: none">
+<h3>Allocations <small title="see Faster.TrackAlloc() for accuracy limits">(per call: <span id="allocsPerCall"></span> allocs, <span id="allocBytesPerCall"></span> bytes)</small></h3>
+<div id="allocChart" style="width: 100%; min-height: 300px;"></div>
+</div>
+
 <h3>Histogram</h3>
 <div id="histogram" style="width: 100%; min-height: 300px;"></div>
 
+<h3>Slowest calls</h3>
+<table id="slowCalls">
+  <thead><tr>
+    <th>finished</th>
+    <th>duration</th>
+    <th>attributes</th>
+    <th title="enable using Faster.SetCaptureStacks()">created at</th>
+  </tr></thead>
+  <tbody></tbody>
+</table>
+<div id="slowCallsEmpty">:: no slow calls recorded (see Faster.SetSlowCallThreshold()) ::</div>
+
 </body>
 <script>
 function fetchData() {
@@ -54,24 +74,38 @@ function fetchData() {
       $('#chart .nodata').remove();
 
       // format data the way flot expects it
-      var counts = [], avgMsec = [];
+      var rate = [], avgMsec = [], p99Msec = [], counter = [], gauge = [];
       for (var i = 0; i < req.ts.length; i++) {
-        counts.push([req.ts[i], req.counts[i]])
+        rate.push([req.ts[i], req.rate[i]])
         avgMsec.push([req.ts[i], req.avgMsec[i]])
+        if (req.p99Msec) p99Msec.push([req.ts[i], req.p99Msec[i]])
+        if (req.counter) counter.push([req.ts[i], req.counter[i]])
+        if (req.gauge) gauge.push([req.ts[i], req.gauge[i]])
       }
 
-      $.plot($("#chart"), [
-          {
-            data: counts,
-            label: "# of calls",
-            bars: {show: true, barWidth: 800, align: "center"},
-          },
-          {
-            data: avgMsec,
-            label: "average duration",
-            yaxis: 2,
-          },
-        ], {
+      var series = [];
+      if (data.total > 0 || data.active > 0 || (!req.counter && !req.gauge)) {
+        series.push({
+          data: rate,
+          label: "calls per second",
+          bars: {show: true, barWidth: 800, align: "right"},
+        }, {
+          data: avgMsec,
+          label: "average duration",
+          yaxis: 2,
+        });
+        if (req.p99Msec) {
+          series.push({data: p99Msec, label: "p99 duration", yaxis: 2});
+        }
+      }
+      if (req.counter) {
+        series.push({data: counter, label: "counter (per interval)", yaxis: 3});
+      }
+      if (req.gauge) {
+        series.push({data: gauge, label: "gauge", yaxis: 4});
+      }
+
+      $.plot($("#chart"), series, {
         xaxis: {
           mode: "time",
           timeBase: "milliseconds",
@@ -85,13 +119,18 @@ function fetchData() {
             tickFormatter: function(v, axis) {
               return v.toFixed(axis.tickDecimals) + "ms";
             },
-          }
+          },
+          {min: 0},
+          {position: "right"},
         ],
       });
     }
 
+    renderAllocs(data);
+    renderSlowCalls(data.slowCalls || []);
+
     var histogram = [];
-    for (var h of data.histogram) {
+    for (var h of data.histogram || []) {
       histogram.push([Math.log2(h.ns), h.count])
     }
 
@@ -126,6 +165,56 @@ function fetchData() {
   })
 }
 
+function renderAllocs(data) {
+  var req = data.requests;
+  $('#allocations').toggle(data.allocsPerCall != null);
+  if (data.allocsPerCall == null) return;
+
+  $('#allocsPerCall').text(data.allocsPerCall.toFixed(1));
+  $('#allocBytesPerCall').text(data.allocBytesPerCall.toFixed(0));
+  if (!req.allocsPerCall) return;
+
+  var allocs = [], bytes = [];
+  for (var i = 0; i < req.ts.length; i++) {
+    allocs.push([req.ts[i], req.allocsPerCall[i]])
+    bytes.push([req.ts[i], req.allocBytesPerCall[i]])
+  }
+  $.plot($("#allocChart"), [
+    {data: allocs, label: "allocs per call"},
+    {data: bytes, label: "bytes per call", yaxis: 2},
+  ], {
+    xaxis: {
+      mode: "time",
+      timeBase: "milliseconds",
+    },
+    yaxes: [
+      {min: 0},
+      {min: 0, position: "right"},
+    ],
+  });
+}
+
+function renderSlowCalls(calls) {
+  var tbody = $('#slowCalls tbody');
+  tbody.empty();
+  $('#slowCalls').toggle(calls.length > 0);
+  $('#slowCallsEmpty').toggle(calls.length == 0);
+
+  for (var call of calls) {
+    var attrs = [];
+    for (var k in call.attrs || {}) {
+      attrs.push(k + '=' + call.attrs[k]);
+    }
+
+    var tr = $('<tr>');
+    tr.append($('<td>').text(new Date(call.ts).toISOString()));
+    tr.append($('<td>').text(call.duration));
+    tr.append($('<td>').text(attrs.join(', ')));
+    tr.append($('<td>').text(call.caller || '-'));
+    tbody.append(tr);
+  }
+}
+
 fetchData();
 </script>
 </html>
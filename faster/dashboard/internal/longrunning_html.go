@@ -0,0 +1,58 @@
+package internal
+
+// LongRunningHTML -- dashboard template listing long running Trackers
+var LongRunningHTML = `
+<html>
+<head><title>long running :: go-faster dashboard</title>
+<style>
+body {
+  font-family: monospace;
+}
+
+th, td { padding-left: 1em; text-align: left; vertical-align: top; }
+tr:hover { background-color: rgba(192,224,255,.5);}
+pre { margin: 0; }
+</style>
+</head>
+<body>
+<h2>go-faster: long running trackers</h2>
+
+<a href="./">Back</a>
+
+<form method="GET" action="longRunning">
+  Active for more than <input name="threshold" value="{{.threshold}}" size="6"/>
+  <button type="submit">Reload</button>
+</form>
+
+{{if .trackers}}
+<table>
+  <thead><tr>
+    <th>Key</th>
+    <th title="time since the tracker's creation">age</th>
+    <th>started</th>
+    <th title="enable using Faster.SetCaptureStacks()">created at</th>
+  </tr></thead>
+  <tbody>
+    {{range .trackers}}
+    <tr>
+      <td><a href="{{keyLink .Path}}">{{range $i, $k := .Path}}{{if $i}} | {{end}}{{$k}}{{end}}</a></td>
+      <td>{{.Age}}</td>
+      <td>{{.StartTS.Format "2006-01-02 15:04:05"}}</td>
+      <td>
+        {{if .Stack}}
+        <details><summary>{{.Caller}}</summary><pre>{{.StackString}}</pre></details>
+        {{else}}
+        <span title="stack capturing is disabled">-</span>
+        {{end}}
+      </td>
+    </tr>
+    {{end}}
+  </tbody>
+</table>
+{{else}}
+<p>:: no trackers active for more than {{.threshold}} ::</p>
+<p>(active trackers are only recorded if enabled using Faster.SetLongRunning(true))</p>
+{{end}}
+</body>
+</html>
+`
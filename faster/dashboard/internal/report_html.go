@@ -0,0 +1,90 @@
+package internal
+
+// ReportHTML -- standalone report template (see dashboard.Report)
+var ReportHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.report.Title}} :: go-faster report</title>
+<style>
+body {
+  font-family: monospace;
+}
+
+th, td { padding-left: 1em; }
+tr:hover { background-color: rgba(192,224,255,.5);}
+
+td { text-align: right; }
+td:first-child, th:first-child { text-align: initial; }
+svg.chart { background-color: #f8f8f8; vertical-align: middle; }
+svg.chart rect { fill: #4a90d9; }
+</style>
+</head>
+<body>
+<h1>go-faster report: {{.report.Title}}</h1>
+
+<table><tbody>
+<tr><th>from</th><td>{{time .report.From}}</td></tr>
+<tr><th>to</th><td>{{time .report.To}}</td></tr>
+{{if .report.Duration}}<tr><th>duration</th><td>{{.report.Duration}}</td></tr>{{end}}
+<tr><th>snapshots</th><td>{{.report.Snapshots}}</td></tr>
+<tr><th>total ms</th><td>{{.totalTime}}</td></tr>
+</tbody></table>
+
+
+<h2>top keys by total time</h2>
+<table>
+  <thead><tr>
+    <th>key</th>
+    <th title="number of finished calls">count</th>
+    <th title="total time spent">total ms</th>
+    <th title="share of the total time of all keys">share</th>
+    <th title="average time spent">average ms</th>
+    <th title="highest number of concurrent calls (of all snapshots)">max active</th>
+  </tr></thead>
+  <tbody>
+    {{range $i, $k := .keys}}
+    <tr>
+      <td><a href="#key-{{$i}}">{{join .Key}}</a></td>
+      <td>{{.Data.Count}}</td>
+      <td title="{{.Data.TotalTime}}">{{.PrettyTotal}}</td>
+      <td>{{percent .Share}}</td>
+      <td title="{{.Data.Average}}">{{.PrettyAverage}}</td>
+      <td>{{or .MaxActive ""}}</td>
+    </tr>
+    {{end}}
+  </tbody>
+</table>
+
+{{if .withPercentiles}}
+<h2>percentiles</h2>
+<table>
+  <thead><tr>
+    <th>key</th>
+    {{range .percentiles}}<th>p{{.}} ms</th>{{end}}
+  </tr></thead>
+  <tbody>
+    {{range .withPercentiles}}
+    <tr>
+      <td>{{join .Key}}</td>
+      {{range .Percentiles}}<td title="{{.}}">{{msec .}}</td>{{end}}
+    </tr>
+    {{end}}
+  </tbody>
+</table>
+{{end}}
+
+{{if .report.Intervals}}
+<h2>per-interval charts</h2>
+<p>{{len .report.Intervals}} intervals ({{.firstInterval}} - {{.lastInterval}})</p>
+{{range $i, $k := .keys}}
+<h3 id="key-{{$i}}">{{join .Key}}</h3>
+<table><tbody>
+<tr><th>count</th><td>{{barChart .Counts ""}}</td></tr>
+<tr><th>average</th><td>{{barChart .Averages "ms"}}</td></tr>
+</tbody></table>
+{{end}}
+{{end}}
+</body>
+</html>
+`
@@ -0,0 +1,41 @@
+package internal
+
+// ReportMarkdown -- Markdown version of ReportHTML
+var ReportMarkdown = `# go-faster report: {{md .report.Title}}
+
+- from: {{time .report.From}}
+- to: {{time .report.To}}
+{{- if .report.Duration}}
+- duration: {{.report.Duration}}
+{{- end}}
+- snapshots: {{.report.Snapshots}}
+- total ms: {{.totalTime}}
+
+## Top keys by total time
+
+| key | count | total ms | share | average ms | max active |
+|-----|------:|---------:|------:|-----------:|-----------:|
+{{- range .keys}}
+| {{md (join .Key)}} | {{.Data.Count}} | {{.PrettyTotal}} | {{percent .Share}} | {{.PrettyAverage}} | {{.MaxActive}} |
+{{- end}}
+{{if .withPercentiles}}
+## Percentiles
+
+| key |{{range .percentiles}} p{{.}} ms |{{end}}
+|-----|{{range .percentiles}}------:|{{end}}
+{{- range .withPercentiles}}
+| {{md (join .Key)}} |{{range .Percentiles}} {{msec .}} |{{end}}
+{{- end}}
+{{end}}
+{{- if .report.Intervals}}
+## Per-interval charts
+
+{{len .report.Intervals}} intervals ({{.firstInterval}} - {{.lastInterval}})
+
+| key | count | average |
+|-----|-------|---------|
+{{- range .keys}}
+| {{md (join .Key)}} | ` + "`{{sparkline .Counts}}`" + ` | ` + "`{{sparkline .Averages}}`" + ` |
+{{- end}}
+{{end -}}
+`
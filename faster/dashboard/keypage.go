@@ -13,7 +13,7 @@ import (
 
 // keyPage -- implements /key/*
 type keyPage struct {
-	faster    *faster.Faster
+	faster    Source
 	templates map[string]*template.Template
 }
 
@@ -37,30 +37,82 @@ func (p *keyPage) InfoJSON(w http.ResponseWriter, r *http.Request) {
 	var selectedTicker *faster.History
 
 	type RequestInfo struct {
-		TS      []int64 `json:"ts"`
-		Counts  []int64 `json:"counts"`
-		AvgMsec []int64 `json:"avgMsec"`
+		TS     []int64 `json:"ts"`
+		Counts []int64 `json:"counts"`
+		// Rate -- calls per second (of each interval)
+		Rate    []float64 `json:"rate"`
+		AvgMsec []int64   `json:"avgMsec"`
+		// P99Msec -- 99th percentile of each interval (only set if histograms are enabled)
+		P99Msec []float64 `json:"p99Msec,omitempty"`
+		// Counter and Gauge values (only set for keys that have one)
+		Counter []int64   `json:"counter,omitempty"`
+		Gauge   []float64 `json:"gauge,omitempty"`
+		// heap allocations per call (only set for keys tracked with TrackAlloc())
+		AllocsPerCall     []float64 `json:"allocsPerCall,omitempty"`
+		AllocBytesPerCall []float64 `json:"allocBytesPerCall,omitempty"`
 	}
 	type Response struct {
 		Requests  RequestInfo              `json:"requests"`
 		Tickers   []map[string]interface{} `json:"tickers"`
 		Histogram []map[string]interface{} `json:"histogram,omitempty"`
+		SlowCalls []map[string]interface{} `json:"slowCalls,omitempty"`
 
-		Active int32 `json:"active"`
-		Total  int64 `json:"total"`
-		AvgMS  int64 `json:"avgMS"`
+		Active  int32    `json:"active"`
+		Total   int64    `json:"total"`
+		AvgMS   int64    `json:"avgMS"`
+		Counter *int64   `json:"counter,omitempty"`
+		Gauge   *float64 `json:"gauge,omitempty"`
+
+		AllocsPerCall     *float64 `json:"allocsPerCall,omitempty"`
+		AllocBytesPerCall *float64 `json:"allocBytesPerCall,omitempty"`
 	}
 	var info Response
 
+	var snap = p.faster.TakeSnapshot()
+	var hasCounter, hasGauge, hasAllocs bool
+	if datapoint := snap.Get(key...); datapoint != nil {
+		info.Active = datapoint.Active()
+		info.AvgMS = int64(datapoint.Average() / time.Millisecond)
+		info.Total = datapoint.Count()
+
+		var counter = datapoint.Counter()
+		var gauge float64
+		gauge, hasGauge = datapoint.Gauge()
+		if hasCounter = datapoint.HasCounter(); hasCounter {
+			info.Counter = &counter
+		}
+		if hasGauge {
+			info.Gauge = &gauge
+		}
+		if hasAllocs = datapoint.AllocCalls() > 0; hasAllocs {
+			var allocs, bytes = allocsPerCall(datapoint)
+			info.AllocsPerCall, info.AllocBytesPerCall = &allocs, &bytes
+		}
+	}
+
 	if len(sortedTickers) > 0 {
 		var req = &info.Requests
 		selectedTicker = p.getTicker(r, tickers, sortedTickers[0])
 		var timeseries = selectedTicker.GetData(key...).Relative()
+		req.Rate = timeseries.Rate()
 		for i, snap := range timeseries.Data {
 			req.TS = append(req.TS, timeseries.GetTimestamp(i).UnixNano()/int64(time.Millisecond))
 			req.Counts = append(req.Counts, snap.Count())
 			req.AvgMsec = append(req.AvgMsec, int64(snap.Average()/time.Millisecond))
+			if hasCounter {
+				req.Counter = append(req.Counter, snap.Counter())
+			}
+			if hasGauge {
+				var gauge, _ = snap.Gauge()
+				req.Gauge = append(req.Gauge, gauge)
+			}
+			if hasAllocs {
+				var allocs, bytes = allocsPerCall(snap)
+				req.AllocsPerCall = append(req.AllocsPerCall, allocs)
+				req.AllocBytesPerCall = append(req.AllocBytesPerCall, bytes)
+			}
 		}
+		req.P99Msec = p99Series(selectedTicker, key)
 
 		for _, h := range sortedTickers {
 			info.Tickers = append(info.Tickers, map[string]interface{}{
@@ -73,13 +125,6 @@ func (p *keyPage) InfoJSON(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	var snap = p.faster.TakeSnapshot()
-	if datapoint := snap.Get(key...); datapoint != nil {
-		info.Active = datapoint.Active()
-		info.AvgMS = int64(datapoint.Average() / time.Millisecond)
-		info.Total = datapoint.Count()
-	}
-
 	if h := snap.GetHistogram(key...); h != nil {
 		var durations, counts = h.GetValues()
 		if len(durations) == len(counts) {
@@ -96,12 +141,64 @@ func (p *keyPage) InfoJSON(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	for _, call := range snap.GetSlowCalls(key...) {
+		info.SlowCalls = append(info.SlowCalls, map[string]interface{}{
+			"ts":       call.TS.UnixNano() / int64(time.Millisecond),
+			"duration": call.Took.String(),
+			"ns":       call.Took / time.Nanosecond,
+			"attrs":    call.Attrs,
+			"caller":   call.Caller(),
+		})
+	}
+
 	w.Header().Set("Content-type", "application/json")
 	if err := json.NewEncoder(w).Encode(info); err != nil {
 		log.Print("Error: failed to encode info.json: ", err)
 	}
 }
 
+// allocsPerCall -- returns the average number of heap allocations (and bytes) of the key's TrackAlloc() calls
+func allocsPerCall(d faster.DataPoint) (allocs, bytes float64) {
+	if calls := d.AllocCalls(); calls > 0 {
+		allocs = float64(d.Allocs()) / float64(calls)
+		bytes = float64(d.AllocBytes()) / float64(calls)
+	}
+	return allocs, bytes
+}
+
+// p99Series -- returns the 99th percentile (in msec) of each interval of the given History
+//
+// The values match History.GetData(key...).Relative() (i.e. there's one for each pair of Snapshots).
+// Returns nil if there are no histograms for the key.
+func p99Series(h *faster.History, key []string) []float64 {
+	var rc []float64
+	var prev *faster.Histogram
+	for i, snap := range h.List() {
+		var hist = snap.GetHistogram(key...)
+		if i > 0 {
+			var p99 float64
+			if hist != nil {
+				var diff = hist
+				if prev != nil {
+					diff = hist.Since(*prev)
+				}
+				if diff.Count() > 0 {
+					p99 = float64(diff.GetPercentile(99)) / float64(time.Millisecond)
+				}
+			}
+			rc = append(rc, p99)
+		}
+		prev = hist
+	}
+
+	for _, v := range rc {
+		if v > 0 {
+			return rc
+		}
+	}
+	return nil
+}
+
 // ServeHTTP -- implements GET key
 func (p *keyPage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if !checkMethod(w, r, "GET") {
@@ -0,0 +1,244 @@
+package dashboard
+
+import (
+	"fmt"
+	htmlTemplate "html/template"
+	"io"
+	"sort"
+	"strings"
+	textTemplate "text/template"
+	"time"
+
+	"github.com/mreithub/go-faster/faster"
+	"github.com/mreithub/go-faster/faster/dashboard/internal"
+)
+
+// reportPercentiles -- percentiles listed for each key (if it has a histogram)
+var reportPercentiles = []int{50, 90, 99}
+
+// Report -- offline summary of a list of Snapshots (e.g. a History dump), see NewReport()
+type Report struct {
+	Title string
+	// From, To -- time range covered by the report
+	From, To time.Time
+	// Snapshots -- number of Snapshots the report was built from
+	Snapshots int
+	// Intervals -- timestamps of the per-interval charts (the end of each interval)
+	Intervals []time.Time
+
+	keys []reportKey
+	// totalTime -- time spent in all keys
+	totalTime time.Duration
+}
+
+// reportKey -- a single key of the report (with the data of the report's time range)
+type reportKey struct {
+	flatEntry
+
+	// Share -- percentage of the report's total time spent in this key
+	Share float64
+	// MaxActive -- highest Active() value of all the Snapshots
+	MaxActive int32
+	// Percentiles -- see reportPercentiles (nil if the key has no histogram)
+	Percentiles []time.Duration
+
+	// per-interval data
+	Counts   []int64
+	Averages []int64 // in msec
+}
+
+// NewReport -- summarizes the given Snapshots, listing the top keys by total time
+//
+// snapshots is expected to be consecutive Snapshots of a single Faster instance
+// (or collector), like the ones written by
+//
+//	faster.NewEncoder(file).EncodeAll(history.List())
+//
+// The report covers the time between the first and the last Snapshot (if there's
+// only one, it covers everything that Snapshot contains)
+func NewReport(title string, snapshots faster.Snapshots, top int) *Report {
+	var rc = Report{
+		Title:     title,
+		Snapshots: len(snapshots),
+	}
+	if len(snapshots) == 0 {
+		return &rc
+	}
+
+	var sorted = make(faster.Snapshots, len(snapshots))
+	copy(sorted, snapshots)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].TS.Before(sorted[j].TS) })
+	var first, last = sorted[0], sorted[len(sorted)-1]
+
+	var period = last
+	rc.To = last.TS
+	if len(sorted) > 1 {
+		period = last.Sub(first)
+		rc.From = first.TS
+	}
+
+	var intervals = make([]*faster.Snapshot, 0, len(sorted)-1)
+	for i := 1; i < len(sorted); i++ {
+		intervals = append(intervals, sorted[i].Sub(sorted[i-1]))
+		rc.Intervals = append(rc.Intervals, sorted[i].TS)
+	}
+
+	for _, entry := range flattenSnapshot(period) {
+		if entry.Data.Count() == 0 {
+			continue
+		}
+		rc.totalTime += entry.Data.TotalTime()
+		rc.keys = append(rc.keys, reportKey{flatEntry: entry})
+	}
+	sort.SliceStable(rc.keys, func(i, j int) bool {
+		return rc.keys[i].Data.TotalTime() > rc.keys[j].Data.TotalTime()
+	})
+	if top > 0 && len(rc.keys) > top {
+		rc.keys = rc.keys[:top]
+	}
+
+	for i := range rc.keys {
+		var k = &rc.keys[i]
+		var key = k.Key()
+		if rc.totalTime > 0 {
+			k.Share = 100 * float64(k.Data.TotalTime()) / float64(rc.totalTime)
+		}
+		if h := period.GetHistogram(key...); h != nil && h.Count() > 0 {
+			k.Percentiles = h.GetPercentiles(reportPercentiles...)
+		}
+		for _, s := range sorted {
+			if d := s.Get(key...); d != nil && d.Active() > k.MaxActive {
+				k.MaxActive = d.Active()
+			}
+		}
+		for _, s := range intervals {
+			var count, avg int64
+			if d := s.Get(key...); d != nil && d.Count() > 0 {
+				count, avg = d.Count(), int64(d.Average()/time.Millisecond)
+			}
+			k.Counts = append(k.Counts, count)
+			k.Averages = append(k.Averages, avg)
+		}
+	}
+
+	return &rc
+}
+
+// Duration -- returns the time range covered by the report (0 if it was built from a single Snapshot)
+func (r *Report) Duration() time.Duration {
+	if r.From.IsZero() {
+		return 0
+	}
+	return r.To.Sub(r.From)
+}
+
+// templateData -- returns the data passed to the report templates
+func (r *Report) templateData() map[string]interface{} {
+	var withPercentiles []reportKey
+	for _, k := range r.keys {
+		if k.Percentiles != nil {
+			withPercentiles = append(withPercentiles, k)
+		}
+	}
+
+	var from, to string
+	if len(r.Intervals) > 0 {
+		from, to = r.Intervals[0].Format(time.RFC3339), r.Intervals[len(r.Intervals)-1].Format(time.RFC3339)
+	}
+	return map[string]interface{}{
+		"report":          r,
+		"keys":            r.keys,
+		"withPercentiles": withPercentiles,
+		"percentiles":     reportPercentiles,
+		"totalTime":       toMsec(r.totalTime),
+		"firstInterval":   from,
+		"lastInterval":    to,
+	}
+}
+
+// WriteHTML -- writes the report as standalone HTML page (with inline SVG charts)
+func (r *Report) WriteHTML(w io.Writer) error {
+	return htmlReportTemplate.Execute(w, r.templateData())
+}
+
+// WriteMarkdown -- writes the report as Markdown document (with unicode sparklines instead of charts)
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	return markdownReportTemplate.Execute(w, r.templateData())
+}
+
+// reportFuncs -- template functions shared by the HTML and Markdown reports
+var reportFuncs = map[string]interface{}{
+	"msec": toMsec,
+	"join": func(path []string) string {
+		return strings.Join(path, " | ")
+	},
+	"percent": func(v float64) string {
+		return fmt.Sprintf("%.1f%%", v)
+	},
+	"time": func(ts time.Time) string {
+		if ts.IsZero() {
+			return "-"
+		}
+		return ts.Format(time.RFC3339)
+	},
+}
+
+var htmlReportTemplate = htmlTemplate.Must(htmlTemplate.New("report.html").Funcs(reportFuncs).Funcs(map[string]interface{}{
+	"barChart": barChart,
+}).Parse(internal.ReportHTML))
+
+var markdownReportTemplate = textTemplate.Must(textTemplate.New("report.md").Funcs(reportFuncs).Funcs(map[string]interface{}{
+	"sparkline": sparkline,
+	"md":        escapeMarkdown,
+}).Parse(internal.ReportMarkdown))
+
+// barChart -- renders the given values as inline SVG bar chart
+func barChart(values []int64, unit string) htmlTemplate.HTML {
+	const width, height = 600, 60
+	var max int64
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	if len(values) == 0 || max == 0 {
+		return ""
+	}
+
+	var barWidth = float64(width) / float64(len(values))
+	var rc strings.Builder
+	fmt.Fprintf(&rc, `<svg class="chart" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	for i, v := range values {
+		var h = float64(v) * height / float64(max)
+		fmt.Fprintf(&rc, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f"><title>%d%s</title></rect>`,
+			float64(i)*barWidth, height-h, barWidth*0.9, h, v, unit)
+	}
+	fmt.Fprintf(&rc, `</svg> <small>max: %d%s</small>`, max, unit)
+	return htmlTemplate.HTML(rc.String())
+}
+
+// sparkline -- renders the given values using unicode block characters
+func sparkline(values []int64) string {
+	var ticks = []rune("▁▂▃▄▅▆▇█")
+	var max int64
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	var rc strings.Builder
+	for _, v := range values {
+		var i int64
+		if max > 0 {
+			i = v * int64(len(ticks)-1) / max
+		}
+		rc.WriteRune(ticks[i])
+	}
+	return rc.String()
+}
+
+// escapeMarkdown -- escapes characters that'd break Markdown tables (or formatting)
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`", "\n", " ").Replace(s)
+}
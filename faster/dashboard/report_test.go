@@ -0,0 +1,72 @@
+package dashboard
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mreithub/go-faster/faster"
+	"github.com/mreithub/go-faster/faster/fastertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportPage(t *testing.T) {
+	var f, clock = fastertest.NewWithClock(t)
+	var snapshots = faster.Snapshots{f.TakeSnapshot()}
+	for i := 1; i <= 3; i++ {
+		for j := 0; j < i; j++ {
+			var ref = f.Track("http", "GET /<script>")
+			clock.Advance(10 * time.Millisecond)
+			ref.Done()
+		}
+		var ref = f.Track("db", "query")
+		clock.Advance(20 * time.Millisecond)
+		ref.Done()
+		clock.Advance(time.Second)
+		snapshots = append(snapshots, f.TakeSnapshot())
+	}
+	f.Track("db", "query") // active while the last Snapshot is taken
+	snapshots = append(snapshots, f.TakeSnapshot())
+
+	var report = NewReport("incident <1>", snapshots, 10)
+	assert.Equal(t, 5, report.Snapshots)
+	assert.Len(t, report.Intervals, 4)
+	var keys = map[string]reportKey{}
+	for _, k := range report.keys {
+		keys[strings.Join(k.Key(), " | ")] = k
+	}
+	if k, ok := keys["http | GET /<script>"]; assert.True(t, ok) {
+		assert.Equal(t, []int64{1, 2, 3, 0}, k.Counts)
+		assert.Equal(t, []int64{10, 10, 10, 0}, k.Averages)
+		assert.Equal(t, int32(0), k.MaxActive)
+		assert.Len(t, k.Percentiles, 3)
+	}
+	if k, ok := keys["db | query"]; assert.True(t, ok) {
+		assert.Equal(t, []int64{1, 1, 1, 0}, k.Counts)
+		assert.Equal(t, int32(1), k.MaxActive)
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, report.WriteHTML(&out))
+	var page = out.String()
+	assert.Contains(t, page, "<h1>go-faster report: incident &lt;1&gt;</h1>")
+	assert.Contains(t, page, "http | GET /&lt;script&gt;")
+	assert.NotContains(t, page, "<script>")
+	assert.Contains(t, page, `<svg class="chart"`)
+
+	out.Reset()
+	assert.NoError(t, report.WriteMarkdown(&out))
+	var md = out.String()
+	assert.Contains(t, md, "# go-faster report: incident <1>")
+	assert.Contains(t, md, "| http \\| GET /<script> | 6 |")
+	assert.Contains(t, md, "▃▅█▁")
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "▁▄█", sparkline([]int64{0, 4, 8}))
+	assert.Equal(t, "▁▁", sparkline([]int64{0, 0}))
+	assert.Equal(t, "", sparkline(nil))
+	assert.Equal(t, `a \| b \*c\*`, escapeMarkdown("a | b *c*"))
+	assert.Empty(t, string(barChart([]int64{0, 0}, "ms")))
+}
@@ -11,11 +11,17 @@ func parseTemplates() (map[string]*template.Template, error) {
 	var tpls = map[string]string{
 		"index.html": internal.IndexHTML,
 		"key.html":   internal.KeyHTML,
+
+		"alerts.html":      internal.AlertsHTML,
+		"longRunning.html": internal.LongRunningHTML,
 	}
 	var rc = map[string]*template.Template{}
 	var err error
 
 	var funcs = map[string]interface{}{
+		"list": func(items ...string) []string {
+			return items
+		},
 		"keyLink": func(key []string) string {
 			var query = url.Values{
 				"k": key,
@@ -11,9 +11,30 @@ type DataPoint interface {
 	TotalTime() time.Duration
 	Average() time.Duration
 
+	// Counter -- sum of all the Counter.Add() calls for this key
+	Counter() int64
+	// HasCounter -- true if Counter.Add() was ever called for this key (even if the sum is 0)
+	HasCounter() bool
+	// Gauge -- current value of the key's Gauge (ok is false if it was never set)
+	Gauge() (value float64, ok bool)
+
+	// AllocCalls -- number of calls whose heap allocations were recorded (see Faster.TrackAlloc())
+	AllocCalls() int64
+	// Allocs -- heap allocations made during those calls
+	Allocs() int64
+	// AllocBytes -- heap bytes allocated during those calls
+	AllocBytes() int64
+
 	Sub(other DataPoint) DataPoint
 }
 
+// kinds of values stored in a data point (besides Track()/Done() counts - see data.kind)
+const (
+	kindCounter = 1 << iota
+	kindGauge
+	kindAllocs
+)
+
 // data -- internal go-faster data structure - thread unsafe (only the go-faster goroutine does writes and creates read-only copies)
 type data struct {
 	// currently active invocations
@@ -22,11 +43,28 @@ type data struct {
 	count int64
 	// time spent in those invocations (in nanoseconds)
 	totalTime time.Duration
+
+	// Counter and Gauge values (see kind)
+	counter int64
+	gauge   float64
+	// allocations recorded by TrackAlloc() Trackers (see kindAllocs)
+	allocCalls int64
+	allocs     int64
+	allocBytes int64
+
+	// bitmask of kindCounter, kindGauge and kindAllocs (set once a Counter, Gauge or TrackAlloc() was used for this key)
+	kind uint8
 }
 
 func (d *data) Active() int32            { return d.active }
 func (d *data) Count() int64             { return d.count }
 func (d *data) TotalTime() time.Duration { return d.totalTime }
+func (d *data) Counter() int64           { return d.counter }
+func (d *data) HasCounter() bool         { return d.kind&kindCounter != 0 }
+func (d *data) Gauge() (float64, bool)   { return d.gauge, d.kind&kindGauge != 0 }
+func (d *data) AllocCalls() int64        { return d.allocCalls }
+func (d *data) Allocs() int64            { return d.allocs }
+func (d *data) AllocBytes() int64        { return d.allocBytes }
 
 // Average -- returns the average time spent in each invocation
 func (d *data) Average() time.Duration {
@@ -38,20 +76,36 @@ func (d *data) Average() time.Duration {
 }
 
 // Done -- caused by Tracker.Done() (and called by Faster.run())
-func (d *data) Done(took time.Duration) {
-	d.active--
-	d.count++
-	d.totalTime += took
+//
+// active won't drop below zero (e.g. if an EvTrack without Tracker was dropped).
+// calls is the number of calls the Tracker stands for (see Sampler, usually 1)
+func (d *data) Done(took time.Duration, calls int64) {
+	if d.active > 0 {
+		d.active--
+	}
+	d.count += calls
+	d.totalTime += took * time.Duration(calls)
 }
 
 // Sub -- returns the difference between the two given Data objects (assuming 'this' is the newer one)
+//
+// If 'this' has a lower count (or Counter value) than other, the key was reset (or evicted) in between (-> d is returned).
+// Gauges keep their current value.
 func (d *data) Sub(other DataPoint) DataPoint {
-	if other == nil {
+	if other == nil || d.count < other.Count() || d.counter < other.Counter() {
 		return d
 	}
 	return &data{
 		active:    0, // doesn't really make sense (maybe we should use max(this, other))
 		count:     d.count - other.Count(),
 		totalTime: d.totalTime - other.TotalTime(),
+		counter:   d.counter - other.Counter(),
+		gauge:     d.gauge,
+
+		allocCalls: d.allocCalls - other.AllocCalls(),
+		allocs:     d.allocs - other.Allocs(),
+		allocBytes: d.allocBytes - other.AllocBytes(),
+
+		kind: d.kind,
 	}
 }
@@ -0,0 +1,110 @@
+package faster
+
+import (
+	"sync/atomic"
+
+	"github.com/mreithub/go-faster/faster/internal"
+)
+
+// DroppedEvents -- number of tracking events dropped because the event buffer was full (see DropWhenFull)
+//
+// Non-zero values mean that Count() and TotalTime() values are undercounted.
+type DroppedEvents struct {
+	// Track -- dropped Track() events (the matching Done() calls are still counted)
+	Track int64 `json:"track"`
+	// Done -- dropped Done() events (i.e. calls that weren't counted - including the child keys of Transport and WatchContext())
+	Done int64 `json:"done"`
+	// Counter -- dropped Counter.Add() calls
+	Counter int64 `json:"counter"`
+	// Gauge -- dropped Gauge.Set() and Gauge.Add() calls
+	Gauge int64 `json:"gauge"`
+}
+
+// Total -- returns the total number of dropped events
+func (d DroppedEvents) Total() int64 {
+	return d.Track + d.Done + d.Counter + d.Gauge
+}
+
+// sub -- returns the difference to an older value (or d itself if the counters were reset in between)
+func (d DroppedEvents) sub(older DroppedEvents) DroppedEvents {
+	if d.Track < older.Track || d.Done < older.Done || d.Counter < older.Counter || d.Gauge < older.Gauge {
+		return d
+	}
+	return DroppedEvents{
+		Track:   d.Track - older.Track,
+		Done:    d.Done - older.Done,
+		Counter: d.Counter - older.Counter,
+		Gauge:   d.Gauge - older.Gauge,
+	}
+}
+
+// add -- returns the sum of both values (used by Snapshots.Merge())
+func (d DroppedEvents) add(other DroppedEvents) DroppedEvents {
+	return DroppedEvents{
+		Track:   d.Track + other.Track,
+		Done:    d.Done + other.Done,
+		Counter: d.Counter + other.Counter,
+		Gauge:   d.Gauge + other.Gauge,
+	}
+}
+
+// DroppedEvents -- returns the number of tracking events that were dropped because the event buffer was full
+//
+// Always zero unless DropWhenFull is set (see WithFullBufferPolicy()). These
+// counters aren't affected by Reset().
+func (f *Faster) DroppedEvents() DroppedEvents {
+	return DroppedEvents{
+		Track:   atomic.LoadInt64(&f.droppedTrack),
+		Done:    atomic.LoadInt64(&f.droppedDone),
+		Counter: atomic.LoadInt64(&f.droppedCounter),
+		Gauge:   atomic.LoadInt64(&f.droppedGauge),
+	}
+}
+
+// DroppedEvents -- returns the number of tracking events dropped by the Faster instance up to the time this Snapshot was taken
+//
+// For Snapshots returned by Sub(), it's the number of events dropped in between.
+func (s *Snapshot) DroppedEvents() DroppedEvents {
+	return s.droppedEvents
+}
+
+// drop -- counts an event that couldn't be enqueued (marking its Tracker if it was a Done() event)
+func (f *Faster) drop(ev *internal.Event) {
+	switch ev.Type {
+	case internal.EvTrack:
+		atomic.AddInt64(&f.droppedTrack, 1)
+	case internal.EvDone:
+		if t, ok := ev.Tracker.(*Tracker); ok {
+			atomic.StoreInt32(&t.doneDropped, 1)
+		}
+		atomic.AddInt64(&f.droppedDone, 1)
+	case internal.EvCall:
+		atomic.AddInt64(&f.droppedDone, 1)
+	case internal.EvCounterAdd:
+		atomic.AddInt64(&f.droppedCounter, 1)
+	case internal.EvGaugeSet, internal.EvGaugeAdd:
+		atomic.AddInt64(&f.droppedGauge, 1)
+	}
+}
+
+// sweepDropped -- removes Trackers whose Done() event was dropped from the active ones
+//
+// should only ever be called from within the run() goroutine (before anything
+// reading the active counts, like takeSnapshot() or longRunning())
+func (f *Faster) sweepDropped() {
+	var dropped = atomic.LoadInt64(&f.droppedDone)
+	if dropped == f.sweptDone {
+		return // nothing new since the last sweep
+	}
+	f.sweptDone = dropped
+
+	for t := range f.active {
+		if atomic.LoadInt32(&t.doneDropped) == 0 {
+			continue
+		}
+		delete(f.active, t)
+		if d := f.getDataForPath(t.path...); d.active > 0 {
+			d.active--
+		}
+	}
+}
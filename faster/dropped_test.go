@@ -0,0 +1,80 @@
+package faster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDroppedEvents(t *testing.T) {
+	var f = New(WithEventBuffer(1), WithFullBufferPolicy(DropWhenFull), WithLongRunning())
+	var a = f.Track("a")
+	var before = f.TakeSnapshot()
+	var release = blockRun(f)
+
+	var x = f.Track("x") // fills up the buffer
+	a.Done()             // dropped
+	var b = f.Track("b") // dropped
+	release()
+
+	// 'a' is no longer active (even though its EvTrack made it)
+	var snap = f.TakeSnapshot()
+	assert.Equal(t, int32(0), snap.Get("a").Active())
+	assert.Equal(t, int64(0), snap.Get("a").Count())
+	assert.Equal(t, int32(1), snap.Get("x").Active())
+	assert.Len(t, f.LongRunning(-1), 1)
+	x.Done()
+	assert.Empty(t, f.LongRunning(-1))
+
+	// the unbalanced Done() is counted, but doesn't make Active() negative
+	b.Done() // the buffer is empty again (LongRunning() waited for the run() goroutine)
+	snap = f.TakeSnapshot()
+	assert.Equal(t, int32(0), snap.Get("b").Active())
+	assert.Equal(t, int64(1), snap.Get("b").Count())
+
+	assert.Equal(t, DroppedEvents{Track: 1, Done: 1}, snap.DroppedEvents())
+	assert.Equal(t, int64(2), snap.DroppedEvents().Total())
+	assert.Equal(t, snap.DroppedEvents(), snap.Sub(before).DroppedEvents())
+	assert.Equal(t, DroppedEvents{}, snap.Sub(snap).DroppedEvents())
+	assert.Equal(t, DroppedEvents{Track: 2, Done: 2}, Snapshots{snap, snap}.Merge().DroppedEvents())
+	if assert.NotNil(t, snap.JSON().DroppedEvents) {
+		assert.Equal(t, int64(1), snap.JSON().DroppedEvents.Done)
+	}
+	assert.Nil(t, before.JSON().DroppedEvents)
+}
+
+func TestDroppedEventsOrder(t *testing.T) {
+	// the Done() event is dropped before the Tracker's EvTrack is processed
+	var f = New(WithEventBuffer(1), WithFullBufferPolicy(DropWhenFull), WithLongRunning())
+	var release = blockRun(f)
+
+	var a = f.Track("a")
+	a.Done()
+	release()
+
+	assert.Nil(t, f.TakeSnapshot().Get("a"))
+	assert.Empty(t, f.LongRunning(-1))
+}
+
+func TestDroppedCall(t *testing.T) {
+	// child keys (like '_canceled') are tracked with a single event - so they can't be dropped halfway
+	var f = New(WithEventBuffer(2), WithFullBufferPolicy(DropWhenFull))
+	var ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	var a = f.Track("a").WatchContext(ctx)
+	var b = f.Track("b").WatchContext(ctx)
+	f.TakeSnapshot()
+
+	var release = blockRun(f)
+	a.Done() // EvDone + '_canceled' call (filling up the buffer)
+	b.Done() // both dropped
+	release()
+
+	var snap = f.TakeSnapshot()
+	assert.Equal(t, int32(0), snap.Get("a", "_canceled").Active())
+	assert.Equal(t, int64(1), snap.Get("a", "_canceled").Count())
+	assert.Nil(t, snap.Get("b", "_canceled"))
+	assert.Equal(t, int32(0), snap.Get("b").Active())
+	assert.Equal(t, DroppedEvents{Done: 2}, snap.DroppedEvents())
+}
@@ -0,0 +1,681 @@
+package faster
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/mreithub/go-faster/faster/internal"
+)
+
+// Binary Snapshot encoding (numbers marked with "u" are unsigned varints - all the other ones are
+// (zigzag-encoded) varints unless noted otherwise):
+//
+//	magic ("GFS") + version (1 byte)
+//	TS: unix seconds + nanoseconds (u)
+//	string table: count (u) + (length (u) + bytes) for each string (key names, Exemplar attributes)
+//	nodes: count (u, excluding the root node) + (parent node (u) + name (u)) for each node (in pre-order, parent 0 is the root node)
+//	for each node (starting with the root node):
+//	  flags (1 byte: hasData, hasHistogram, hasSlowCalls, hasMetrics)
+//	  data: active + count + totalTime
+//	  metrics: kind (1 byte: counter, gauge, allocs) + counter (if set) + gauge (if set, float64 bits - 8 bytes little endian)
+//	    + allocCalls + allocs + allocBytes (if set)
+//	  histogram: count + sum + first bucket (u) + number of buckets (u) + bucket values (each one relative to the one before it)
+//	  slow calls: count (u) + (TS + took + attribute count (u) + (key (u) + value (u)) for each attribute) for each Exemplar
+//	dropped keys: count (u) + (path length (u) + path (u) + distinct) for each overflow node
+//	dropped events: Track + Done + Counter + Gauge
+//	sample rates: per-instance rate + count (u) + (path length (u) + path (u) + rate) for each sampled key
+//
+// Note that Exemplar stacks aren't encoded (they're process-specific)
+
+const (
+	binaryMagic   = "GFS"
+	binaryVersion = 1
+
+	// maxBinaryDepth -- keys nested deeper than this can't be encoded (and will be rejected by the decoder)
+	maxBinaryDepth = 100
+	// maxBinaryFrame -- upper limit for the size of a single Snapshot in a Decoder stream
+	maxBinaryFrame = 64 << 20
+)
+
+const (
+	flagData = 1 << iota
+	flagHistogram
+	flagSlowCalls
+	flagMetrics
+)
+
+// ErrInvalidSnapshot -- returned when decoding malformed binary Snapshot data
+var ErrInvalidSnapshot = errors.New("faster: invalid binary snapshot")
+
+// ErrUnsupportedVersion -- returned when decoding binary Snapshots of an unknown version
+var ErrUnsupportedVersion = errors.New("faster: unsupported binary snapshot version")
+
+var errTooDeep = errors.New("faster: key too deep for binary encoding")
+
+// binWriter -- helper for writing varints and strings
+type binWriter struct {
+	bytes.Buffer
+	tmp [binary.MaxVarintLen64]byte
+}
+
+func (w *binWriter) uvarint(v uint64) {
+	var n = binary.PutUvarint(w.tmp[:], v)
+	w.Write(w.tmp[:n])
+}
+
+func (w *binWriter) varint(v int64) {
+	var n = binary.PutVarint(w.tmp[:], v)
+	w.Write(w.tmp[:n])
+}
+
+func (w *binWriter) float(v float64) {
+	binary.LittleEndian.PutUint64(w.tmp[:8], math.Float64bits(v))
+	w.Write(w.tmp[:8])
+}
+func (w *binWriter) time(ts time.Time) {
+	w.varint(ts.Unix())
+	w.uvarint(uint64(ts.Nanosecond()))
+}
+
+// stringTable -- assigns IDs to strings (in the order they were added)
+type stringTable struct {
+	ids  map[string]uint64
+	list []string
+}
+
+func (t *stringTable) add(s string) {
+	if _, ok := t.ids[s]; !ok {
+		t.ids[s] = uint64(len(t.list))
+		t.list = append(t.list, s)
+	}
+}
+
+// encodedNode -- a tree node (+ its data) to be encoded
+type encodedNode struct {
+	parent int
+	name   string
+	path   []string
+}
+
+// MarshalBinary -- implements encoding.BinaryMarshaler (see UnmarshalBinary())
+func (s *Snapshot) MarshalBinary() ([]byte, error) {
+	// collect nodes and strings first
+	var nodes = []encodedNode{{parent: -1}}
+	var strs = stringTable{ids: make(map[string]uint64)}
+	var nodeIDs = map[string]int{"": 0}
+	var err error
+
+	s.walk(func(path []string) {
+		if len(path) == 0 {
+			return
+		} else if len(path) > maxBinaryDepth {
+			err = errTooDeep
+			return
+		}
+
+		var name = path[len(path)-1]
+		strs.add(name)
+		nodeIDs[pathKey(path)] = len(nodes)
+		nodes = append(nodes, encodedNode{
+			parent: nodeIDs[pathKey(path[:len(path)-1])],
+			name:   name,
+			path:   path,
+		})
+
+		for _, e := range s.getSlowCalls(path) {
+			for k, v := range e.Attrs {
+				strs.add(k)
+				strs.add(v)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range s.dropped {
+		for _, name := range d.Path {
+			strs.add(name)
+		}
+	}
+	var sampled = s.sampledKeys()
+	for _, path := range sampled {
+		for _, name := range path {
+			strs.add(name)
+		}
+	}
+
+	var w binWriter
+	w.WriteString(binaryMagic)
+	w.WriteByte(binaryVersion)
+	w.time(s.TS)
+
+	w.uvarint(uint64(len(strs.list)))
+	for _, str := range strs.list {
+		w.uvarint(uint64(len(str)))
+		w.WriteString(str)
+	}
+
+	w.uvarint(uint64(len(nodes) - 1))
+	for _, node := range nodes[1:] {
+		w.uvarint(uint64(node.parent))
+		w.uvarint(strs.ids[node.name])
+	}
+
+	for _, node := range nodes {
+		s.encodeNode(&w, &strs, node.path)
+	}
+
+	w.uvarint(uint64(len(s.dropped)))
+	for _, d := range s.dropped {
+		w.uvarint(uint64(len(d.Path)))
+		for _, name := range d.Path {
+			w.uvarint(strs.ids[name])
+		}
+		w.varint(int64(d.Distinct))
+	}
+	w.varint(s.droppedEvents.Track)
+	w.varint(s.droppedEvents.Done)
+	w.varint(s.droppedEvents.Counter)
+	w.varint(s.droppedEvents.Gauge)
+
+	w.varint(int64(s.sampleRate))
+	w.uvarint(uint64(len(sampled)))
+	for _, path := range sampled {
+		w.uvarint(uint64(len(path)))
+		for _, name := range path {
+			w.uvarint(strs.ids[name])
+		}
+		w.varint(int64(s.sampleRates[pathKey(path)]))
+	}
+
+	return w.Bytes(), nil
+}
+
+// encodeNode -- writes the data, histogram and slow calls of the given key
+func (s *Snapshot) encodeNode(w *binWriter, strs *stringTable, path []string) {
+	var d = s.getData(path)
+	var h = s.getHistogram(path)
+	var calls = s.getSlowCalls(path)
+
+	var flags byte
+	if d != nil && (d.active != 0 || d.count != 0 || d.totalTime != 0) {
+		flags |= flagData
+	}
+	if d != nil && d.kind != 0 {
+		flags |= flagMetrics
+	}
+	if h != nil && (h.count != 0 || h.sum != 0) {
+		flags |= flagHistogram
+	}
+	if len(calls) > 0 {
+		flags |= flagSlowCalls
+	}
+	w.WriteByte(flags)
+
+	if flags&flagData != 0 {
+		w.varint(int64(d.active))
+		w.varint(d.count)
+		w.varint(int64(d.totalTime))
+	}
+
+	if flags&flagMetrics != 0 {
+		w.WriteByte(d.kind)
+		if d.kind&kindCounter != 0 {
+			w.varint(d.counter)
+		}
+		if d.kind&kindGauge != 0 {
+			w.float(d.gauge)
+		}
+		if d.kind&kindAllocs != 0 {
+			w.varint(d.allocCalls)
+			w.varint(d.allocs)
+			w.varint(d.allocBytes)
+		}
+	}
+
+	if flags&flagHistogram != 0 {
+		w.varint(h.count)
+		w.varint(int64(h.sum))
+
+		// only write the non-empty range of buckets (delta-encoded)
+		var first, last = 0, len(h.buckets) - 1
+		for first <= last && h.buckets[first] == 0 {
+			first++
+		}
+		for last >= first && h.buckets[last] == 0 {
+			last--
+		}
+		if first > last {
+			first, last = 0, -1 // all the buckets are empty (count and sum might not be)
+		}
+		w.uvarint(uint64(first))
+		w.uvarint(uint64(last - first + 1))
+		var prev int32
+		for _, v := range h.buckets[first : last+1] {
+			w.varint(int64(v) - int64(prev))
+			prev = v
+		}
+	}
+
+	if flags&flagSlowCalls != 0 {
+		w.uvarint(uint64(len(calls)))
+		for _, e := range calls {
+			w.time(e.TS)
+			w.varint(int64(e.Took))
+			w.uvarint(uint64(len(e.Attrs)))
+			for k, v := range e.Attrs {
+				w.uvarint(strs.ids[k])
+				w.uvarint(strs.ids[v])
+			}
+		}
+	}
+}
+
+// getSlowCalls -- returns the (unresolved) slow calls of the given key
+func (s *Snapshot) getSlowCalls(path []string) []Exemplar {
+	if s == nil || s.tree == nil {
+		return nil
+	}
+	return s.slowCalls[s.tree.GetIndex(path...)]
+}
+
+// pathKey -- returns a unique map key for the given path (see splitPathKey())
+//
+// Each name is terminated by "\x00\x00" (zero bytes within names are escaped as "\x00\x01"),
+// so e.g. [] and [""] get different keys. Sorting keys sorts their paths.
+func pathKey(path []string) string {
+	var rc bytes.Buffer
+	for _, name := range path {
+		if strings.IndexByte(name, 0) < 0 {
+			rc.WriteString(name)
+		} else {
+			rc.WriteString(strings.Replace(name, "\x00", "\x00\x01", -1))
+		}
+		rc.WriteString("\x00\x00")
+	}
+	return rc.String()
+}
+
+// splitPathKey -- returns the path of a key returned by pathKey()
+func splitPathKey(key string) []string {
+	var rc []string
+	for key != "" {
+		var end = strings.Index(key, "\x00\x00")
+		if end < 0 {
+			break // not returned by pathKey()
+		}
+		rc = append(rc, strings.Replace(key[:end], "\x00\x01", "\x00", -1))
+		key = key[end+2:]
+	}
+	return rc
+}
+
+// binReader -- helper for reading varints and strings (any error will be stored in .err)
+type binReader struct {
+	buf []byte
+	err error
+}
+
+func (r *binReader) fail() {
+	if r.err == nil {
+		r.err = ErrInvalidSnapshot
+	}
+}
+
+func (r *binReader) byte() byte {
+	if r.err != nil || len(r.buf) == 0 {
+		r.fail()
+		return 0
+	}
+	var rc = r.buf[0]
+	r.buf = r.buf[1:]
+	return rc
+}
+
+func (r *binReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	var rc, n = binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.fail()
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return rc
+}
+
+func (r *binReader) float() float64 {
+	if r.err != nil || len(r.buf) < 8 {
+		r.fail()
+		return 0
+	}
+	var rc = math.Float64frombits(binary.LittleEndian.Uint64(r.buf))
+	r.buf = r.buf[8:]
+	return rc
+}
+func (r *binReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	var rc, n = binary.Varint(r.buf)
+	if n <= 0 {
+		r.fail()
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return rc
+}
+
+// count -- reads the number of items to follow (each of them taking up at least one byte)
+func (r *binReader) count() int {
+	var rc = r.uvarint()
+	if rc > uint64(len(r.buf)) {
+		r.fail()
+		return 0
+	}
+	return int(rc)
+}
+
+// ref -- reads an index into a list of the given length
+func (r *binReader) ref(length int) int {
+	var rc = r.uvarint()
+	if rc >= uint64(length) {
+		r.fail()
+		return 0
+	}
+	return int(rc)
+}
+
+// str -- reads a string table reference
+func (r *binReader) str(strs []string) string {
+	if len(strs) == 0 {
+		r.fail()
+		return ""
+	}
+	return strs[r.ref(len(strs))]
+}
+
+func (r *binReader) time() time.Time {
+	var sec = r.varint()
+	var nsec = r.uvarint()
+	if nsec >= uint64(time.Second) {
+		r.fail()
+		return time.Time{}
+	}
+	return time.Unix(sec, int64(nsec))
+}
+
+// UnmarshalBinary -- implements encoding.BinaryUnmarshaler (decoding the format written by MarshalBinary())
+func (s *Snapshot) UnmarshalBinary(buf []byte) error {
+	if len(buf) < len(binaryMagic)+1 || string(buf[:len(binaryMagic)]) != binaryMagic {
+		return ErrInvalidSnapshot
+	}
+	if buf[len(binaryMagic)] != binaryVersion {
+		return ErrUnsupportedVersion
+	}
+
+	var r = binReader{buf: buf[len(binaryMagic)+1:]}
+	var ts = r.time()
+
+	var strs = make([]string, r.count())
+	for i := range strs {
+		var length = r.count()
+		if r.err != nil {
+			return r.err
+		}
+		strs[i] = string(r.buf[:length])
+		r.buf = r.buf[length:]
+	}
+
+	var b = snapshotBuilder{
+		slowCalls: make(map[int][]Exemplar),
+	}
+	// nodes are stored as parent reference + name (paths are assembled in a reusable buffer)
+	var nodeCount = r.count() + 1
+	var parents, names, depths = make([]int, nodeCount), make([]string, nodeCount), make([]int, nodeCount)
+	var indexes = make([]int, nodeCount)
+	var path = make([]string, 0, maxBinaryDepth)
+	indexes[0] = b.index(nil)
+	for i := 1; i < nodeCount && r.err == nil; i++ {
+		parents[i], names[i] = r.ref(i), r.str(strs)
+		depths[i] = depths[parents[i]] + 1
+		if depths[i] > maxBinaryDepth {
+			return ErrInvalidSnapshot
+		}
+
+		path = path[:depths[i]]
+		for node := i; node > 0; node = parents[node] {
+			path[depths[node]-1] = names[node]
+		}
+		if b.tree.Exists(path...) {
+			return ErrInvalidSnapshot // duplicate node
+		}
+		indexes[i] = b.index(path)
+	}
+
+	for i := 0; i < len(indexes) && r.err == nil; i++ {
+		b.decodeNode(&r, strs, indexes[i])
+	}
+
+	var dropped = make([]internal.Dropped, r.count())
+	for i := range dropped {
+		var path = make([]string, r.count())
+		for j := range path {
+			path[j] = r.str(strs)
+		}
+		dropped[i] = internal.Dropped{
+			Path:     path,
+			Distinct: int(r.varint()),
+		}
+	}
+
+	var droppedEvents DroppedEvents
+	droppedEvents.Track, droppedEvents.Done = r.varint(), r.varint()
+	droppedEvents.Counter, droppedEvents.Gauge = r.varint(), r.varint()
+
+	var sampleRate = int(r.varint())
+	var sampleRates map[string]int
+	var sampledCount = r.count()
+	for i := 0; i < sampledCount && r.err == nil; i++ {
+		var path = make([]string, r.count())
+		for j := range path {
+			path[j] = r.str(strs)
+		}
+		sampleRates = mergeSampleRates(sampleRates, map[string]int{pathKey(path): int(r.varint())})
+	}
+
+	if r.err != nil {
+		return r.err
+	} else if len(r.buf) > 0 {
+		return ErrInvalidSnapshot // trailing garbage
+	}
+
+	*s = *b.build(ts, dropped)
+	s.droppedEvents = droppedEvents
+	s.sampleRate, s.sampleRates = sampleRate, sampleRates
+	return nil
+}
+
+// decodeNode -- reads a node's data, histogram and slow calls (see encodeNode())
+func (b *snapshotBuilder) decodeNode(r *binReader, strs []string, index int) {
+	var flags = r.byte()
+	if flags&^(flagData|flagHistogram|flagSlowCalls|flagMetrics) != 0 {
+		r.fail()
+		return
+	}
+
+	if flags&flagData != 0 {
+		var d = b.store.getData(index)
+		d.active = int32(r.varint())
+		d.count = r.varint()
+		d.totalTime = time.Duration(r.varint())
+	}
+
+	if flags&flagMetrics != 0 {
+		var d = b.store.getData(index)
+		if d.kind = r.byte(); d.kind&^(kindCounter|kindGauge|kindAllocs) != 0 {
+			r.fail()
+			return
+		}
+		if d.kind&kindCounter != 0 {
+			d.counter = r.varint()
+		}
+		if d.kind&kindGauge != 0 {
+			d.gauge = r.float()
+		}
+		if d.kind&kindAllocs != 0 {
+			d.allocCalls = r.varint()
+			d.allocs = r.varint()
+			d.allocBytes = r.varint()
+		}
+	}
+
+	if flags&flagHistogram != 0 {
+		var h = b.store.getHistogram(index)
+		h.count = r.varint()
+		h.sum = time.Duration(r.varint())
+
+		var first = r.ref(len(h.buckets))
+		var n = r.uvarint()
+		if n > uint64(len(h.buckets)-first) {
+			r.fail()
+			return
+		}
+		var prev int64
+		for i := first; i < first+int(n); i++ {
+			prev += r.varint()
+			h.buckets[i] = int32(prev)
+		}
+	}
+
+	if flags&flagSlowCalls != 0 {
+		var calls = make([]Exemplar, r.count())
+		for i := range calls {
+			calls[i].TS = r.time()
+			calls[i].Took = time.Duration(r.varint())
+			if n := r.count(); n > 0 {
+				calls[i].Attrs = make(map[string]string, n)
+				for j := 0; j < n; j++ {
+					var k = r.str(strs)
+					calls[i].Attrs[k] = r.str(strs)
+				}
+			}
+		}
+		b.slowCalls[index] = calls
+	}
+}
+
+// Encoder -- writes a stream of binary encoded Snapshots (see Snapshot.MarshalBinary() and Decoder)
+type Encoder struct {
+	w   io.Writer
+	buf [binary.MaxVarintLen64]byte
+}
+
+// NewEncoder -- returns an Encoder writing to w
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode -- writes the given Snapshot (prefixed with its length)
+func (e *Encoder) Encode(s *Snapshot) error {
+	var data, err = s.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	var n = binary.PutUvarint(e.buf[:], uint64(len(data)))
+	if _, err = e.w.Write(e.buf[:n]); err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+// EncodeAll -- writes all the given Snapshots
+func (e *Encoder) EncodeAll(l Snapshots) error {
+	for _, s := range l {
+		if err := e.Encode(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decoder -- reads a stream of Snapshots written by an Encoder
+type Decoder struct {
+	r *bufio.Reader
+}
+
+// NewDecoder -- returns a Decoder reading from r
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode -- reads the next Snapshot (returns io.EOF at the end of the stream)
+func (d *Decoder) Decode() (*Snapshot, error) {
+	var length, err = binary.ReadUvarint(d.r)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, ErrInvalidSnapshot
+	} else if length > maxBinaryFrame {
+		return nil, ErrInvalidSnapshot
+	}
+
+	var buf = make([]byte, length)
+	if _, err = io.ReadFull(d.r, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	var rc Snapshot
+	if err = rc.UnmarshalBinary(buf); err != nil {
+		return nil, err
+	}
+	return &rc, nil
+}
+
+// DecodeAll -- reads all remaining Snapshots of the stream
+func (d *Decoder) DecodeAll() (Snapshots, error) {
+	var rc Snapshots
+	for {
+		var s, err = d.Decode()
+		if err == io.EOF {
+			return rc, nil
+		} else if err != nil {
+			return rc, err
+		}
+		rc = append(rc, s)
+	}
+}
+
+// ReadSnapshots -- reads all Snapshots from r, which may either contain a stream
+// written by an Encoder or a single Snapshot (see MarshalBinary())
+func ReadSnapshots(r io.Reader) (Snapshots, error) {
+	var in = bufio.NewReader(r)
+
+	// single Snapshots start with the magic, streams with a length prefix
+	if prefix, _ := in.Peek(len(binaryMagic)); string(prefix) == binaryMagic {
+		var data, err = ioutil.ReadAll(in)
+		if err != nil {
+			return nil, err
+		}
+		var rc Snapshot
+		if err = rc.UnmarshalBinary(data); err != nil {
+			return nil, err
+		}
+		return Snapshots{&rc}, nil
+	}
+	return NewDecoder(in).DecodeAll()
+}
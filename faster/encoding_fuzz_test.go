@@ -0,0 +1,44 @@
+//go:build go1.18
+// +build go1.18
+
+package faster
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	var buf, _ = testSnapshot().MarshalBinary()
+	f.Add(buf)
+	buf, _ = (&Snapshot{}).MarshalBinary()
+	f.Add(buf)
+	f.Add([]byte("GFS\x01"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var snap Snapshot
+		if err := snap.UnmarshalBinary(data); err != nil {
+			return
+		}
+
+		// anything we can decode has to survive a round trip
+		var encoded, err = snap.MarshalBinary()
+		if err != nil {
+			t.Fatal("failed to re-encode snapshot: ", err)
+		}
+		var decoded Snapshot
+		if err = decoded.UnmarshalBinary(encoded); err != nil {
+			t.Fatal("failed to decode re-encoded snapshot: ", err)
+		}
+	})
+}
+
+func FuzzDecoder(f *testing.F) {
+	var buf bytes.Buffer
+	NewEncoder(&buf).EncodeAll(Snapshots{testSnapshot(), &Snapshot{}})
+	f.Add(buf.Bytes())
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		NewDecoder(bytes.NewReader(data)).DecodeAll()
+	})
+}
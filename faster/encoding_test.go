@@ -0,0 +1,198 @@
+package faster
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/mreithub/go-faster/faster/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+// testSnapshot -- returns a Snapshot containing data, histograms, slow calls and dropped keys
+func testSnapshot() *Snapshot {
+	f := New()
+	f.SetSlowCallThreshold(time.Millisecond, "slow")
+	f.SetFanOutLimit(2, "http")
+	trackN(f, 2, time.Second, "http", "GET /")
+	trackN(f, 1, 3*time.Millisecond, "http", "POST /")
+	trackN(f, 1, time.Millisecond, "http", "PUT /")
+	trackN(f, 5, 100*time.Microsecond, "db", "query", "SELECT")
+	f.Track("db", "query")
+	f.Counter("db", "rows").Add(42)
+	f.Gauge("queue").Set(2.5)
+	f.TrackAlloc("alloc").Done()
+
+	var ref = f.Track("slow").SetAttr("requestID", "1234")
+	time.Sleep(2 * time.Millisecond)
+	ref.Done()
+
+	return f.TakeSnapshot()
+}
+
+// assertSnapshotsEqual -- compares all the keys of both Snapshots
+func assertSnapshotsEqual(t *testing.T, expected, actual *Snapshot) {
+	assert.True(t, expected.TS.Equal(actual.TS))
+	assert.Equal(t, expected.DroppedKeys(), actual.DroppedKeys())
+	assert.Equal(t, expected.DroppedEvents(), actual.DroppedEvents())
+
+	var count = 0
+	expected.walk(func(path []string) {
+		count++
+		assert.True(t, actual.tree.Exists(path...), "missing key: %v", path)
+		assert.ElementsMatch(t, expected.Children(path...), actual.Children(path...))
+		if d := expected.getData(path); d != nil && *d != (data{}) {
+			assert.Equal(t, d, actual.getData(path))
+		}
+		if h := expected.getHistogram(path); h != nil && h.count > 0 {
+			assert.Equal(t, h, actual.getHistogram(path))
+		}
+
+		var calls = actual.GetSlowCalls(path...)
+		assert.Len(t, calls, len(expected.GetSlowCalls(path...)))
+		for i, e := range expected.GetSlowCalls(path...) {
+			assert.True(t, e.TS.Equal(calls[i].TS))
+			assert.Equal(t, e.Took, calls[i].Took)
+			assert.Equal(t, e.Attrs, calls[i].Attrs)
+		}
+	})
+	assert.True(t, count > 1)
+}
+
+func TestMarshalBinary(t *testing.T) {
+	var snap = testSnapshot()
+	snap.droppedEvents = DroppedEvents{Track: 3, Done: 300}
+	var buf, err = snap.MarshalBinary()
+	assert.NoError(t, err)
+
+	var decoded Snapshot
+	assert.NoError(t, decoded.UnmarshalBinary(buf))
+	assertSnapshotsEqual(t, snap, &decoded)
+	assert.Equal(t, "1234", decoded.GetSlowCalls("slow")[0].Attrs["requestID"])
+	assert.Equal(t, int32(1), decoded.Get("db", "query").Active())
+
+	// re-encoding yields the same size (the order of nodes might differ)
+	var buf2, _ = decoded.MarshalBinary()
+	assert.Equal(t, len(buf), len(buf2))
+
+	// empty Snapshot
+	buf, err = (&Snapshot{}).MarshalBinary()
+	assert.NoError(t, err)
+	assert.NoError(t, decoded.UnmarshalBinary(buf))
+	assert.Nil(t, decoded.Get("http"))
+
+	// errors
+	assert.Equal(t, ErrInvalidSnapshot, decoded.UnmarshalBinary(nil))
+	assert.Equal(t, ErrInvalidSnapshot, decoded.UnmarshalBinary([]byte("{}")))
+	assert.Equal(t, ErrUnsupportedVersion, decoded.UnmarshalBinary([]byte("GFS\x02")))
+	buf, _ = snap.MarshalBinary()
+	assert.Equal(t, ErrInvalidSnapshot, decoded.UnmarshalBinary(buf[:len(buf)-1]))
+	assert.Equal(t, ErrInvalidSnapshot, decoded.UnmarshalBinary(append(buf, 0)))
+
+	var tree internal.RWTree
+	var path = make([]string, maxBinaryDepth+1)
+	tree.GetIndex(path...)
+	_, err = (&Snapshot{tree: tree.Clone()}).MarshalBinary()
+	assert.Error(t, err)
+}
+
+// TestBinarySize -- the binary encoding should be a lot more compact than JSON
+func TestBinarySize(t *testing.T) {
+	var snap = testSnapshot()
+	var buf, _ = snap.MarshalBinary()
+	assert.True(t, len(buf) < 300, "binary snapshot too large: %d bytes", len(buf))
+}
+
+func TestMarshalEmptyNames(t *testing.T) {
+	f := New()
+	trackN(f, 1, time.Second, "")
+	trackN(f, 2, time.Second, "", "")
+	trackN(f, 3, time.Second, "a")
+	var snap = f.TakeSnapshot()
+
+	var buf, err = snap.MarshalBinary()
+	assert.NoError(t, err)
+	var decoded Snapshot
+	if assert.NoError(t, decoded.UnmarshalBinary(buf)) {
+		assertSnapshotsEqual(t, snap, &decoded)
+		assert.Equal(t, int64(3), decoded.Get("a").Count())
+		assert.Equal(t, int64(2), decoded.Get("", "").Count())
+	}
+}
+
+func TestPathKey(t *testing.T) {
+	var paths = [][]string{nil, {""}, {"", ""}, {"a"}, {"a", ""}, {"a\x00"}, {"a\x00", ""}, {"a", "b"}, {"ab"}, {"b"}}
+	var keys = map[string]bool{}
+	for _, path := range paths {
+		var key = pathKey(path)
+		assert.False(t, keys[key], "duplicate key for %q", path)
+		keys[key] = true
+		assert.Equal(t, path, splitPathKey(key))
+	}
+
+	// keys sort like their paths
+	assert.True(t, pathKey([]string{"a"}) < pathKey([]string{"a", "b"}))
+	assert.True(t, pathKey([]string{"a", "b"}) < pathKey([]string{"ab"}))
+}
+
+func TestMarshalEmptyBuckets(t *testing.T) {
+	// histograms may have a count (and sum) without any non-empty buckets (e.g. decoded or merged ones)
+	var snap = testSnapshot()
+	var h = snap.getHistogram([]string{"http", "GET /"})
+	h.buckets = [64]int32{}
+
+	var buf, err = snap.MarshalBinary()
+	assert.NoError(t, err)
+	var decoded Snapshot
+	if assert.NoError(t, decoded.UnmarshalBinary(buf)) {
+		assert.Equal(t, h, decoded.getHistogram([]string{"http", "GET /"}))
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	var snaps = Snapshots{testSnapshot(), testSnapshot(), &Snapshot{}}
+	var buf bytes.Buffer
+	assert.NoError(t, NewEncoder(&buf).EncodeAll(snaps))
+	var data = buf.Bytes()
+
+	var decoded, err = NewDecoder(bytes.NewReader(data)).DecodeAll()
+	assert.NoError(t, err)
+	assert.Len(t, decoded, 3)
+	assertSnapshotsEqual(t, snaps[1], decoded[1])
+
+	var dec = NewDecoder(bytes.NewReader(data[:len(data)-1]))
+	_, err = dec.Decode()
+	assert.NoError(t, err)
+	_, err = dec.Decode()
+	assert.NoError(t, err)
+	_, err = dec.Decode()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	_, err = NewDecoder(bytes.NewReader(nil)).Decode()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReadSnapshots(t *testing.T) {
+	var snaps = Snapshots{testSnapshot(), testSnapshot()}
+	var buf bytes.Buffer
+	assert.NoError(t, NewEncoder(&buf).EncodeAll(snaps))
+
+	var list, err = ReadSnapshots(&buf)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assertSnapshotsEqual(t, snaps[1], list[1])
+
+	// single Snapshot
+	data, _ := snaps[0].MarshalBinary()
+	list, err = ReadSnapshots(bytes.NewReader(data))
+	assert.NoError(t, err)
+	if assert.Len(t, list, 1) {
+		assertSnapshotsEqual(t, snaps[0], list[0])
+	}
+
+	_, err = ReadSnapshots(bytes.NewReader([]byte("GFS\x01")))
+	assert.Error(t, err)
+	_, err = ReadSnapshots(bytes.NewReader([]byte("invalid")))
+	assert.Error(t, err)
+}
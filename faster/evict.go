@@ -0,0 +1,88 @@
+package faster
+
+import (
+	"time"
+
+	"github.com/mreithub/go-faster/faster/internal"
+)
+
+// SetEvictAfter -- removes keys that haven't been tracked for the given duration (and have no active Trackers)
+//
+// Use this in long-running services to get rid of keys of old routes, tenants, etc.
+// (the slots of evicted keys will be reused for new ones). Idle keys are checked
+// every idle/2, so they will be evicted idle to 1.5*idle after their last use.
+// Parent keys are evicted once all of their children are gone. Keys holding a
+// Counter or Gauge value are never evicted (their value can't be recreated).
+//
+// Snapshots (and History entries) aren't affected. If an evicted key is tracked again,
+// its values will start at 0 (DataPoint.Sub() and Histogram.Since() will treat that
+// like a reset). Set to 0 to disable eviction again (the default).
+func (f *Faster) SetEvictAfter(idle time.Duration) {
+	f.do(internal.EvSetEvictAfter, nil, idle)
+}
+
+// setEvictAfter -- internal counterpart of SetEvictAfter() (called by the run() goroutine)
+func (f *Faster) setEvictAfter(now time.Time, idle time.Duration) {
+	if idle > 0 && f.evictAfter <= 0 {
+		// we haven't kept track of key usage so far -> start counting now
+		f.lastUsed = make([]time.Time, f.store.len())
+		for i := range f.lastUsed {
+			f.lastUsed[i] = now
+		}
+	} else if idle <= 0 {
+		f.lastUsed = nil
+	}
+	f.evictAfter = idle
+}
+
+// touch -- updates the last use timestamp of the given index (if eviction is enabled)
+//
+// Uses the time of the event's Tracker if possible (so it doesn't matter when the event gets processed).
+// ev may be nil (-> uses the current time)
+func (f *Faster) touch(index int, ev *internal.Event) {
+	if f.evictAfter <= 0 {
+		return
+	}
+	if index >= len(f.lastUsed) {
+		f.lastUsed = append(f.lastUsed, make([]time.Time, index-len(f.lastUsed)+1)...)
+	}
+
+	var now time.Time
+	if ev != nil {
+		if t, ok := ev.Tracker.(*Tracker); ok && !t.startTS.IsZero() {
+			now = t.startTS.Add(ev.Took)
+		}
+	}
+	if now.IsZero() {
+		now = f.clock.Now()
+	}
+	f.lastUsed[index] = now
+}
+
+// evict -- removes idle keys, resetting their slots (called by the run() goroutine)
+func (f *Faster) evict(now time.Time) {
+	if f.evictAfter <= 0 {
+		return
+	}
+
+	var cutoff = now.Add(-f.evictAfter)
+	var evicted = f.tree.Evict(func(index int) bool {
+		if d := f.store.readData(index); d != nil && (d.active > 0 || d.kind&(kindCounter|kindGauge) != 0) {
+			return false
+		}
+		return index >= len(f.lastUsed) || f.lastUsed[index].Before(cutoff)
+	})
+
+	for _, index := range evicted {
+		if f.store.readData(index) != nil {
+			*f.store.getData(index) = data{}
+		}
+		if f.store.readHistogram(index) != nil {
+			*f.store.getHistogram(index) = Histogram{}
+		}
+		if index < len(f.lastUsed) {
+			f.lastUsed[index] = time.Time{}
+		}
+		delete(f.slowCalls, index)
+	}
+}
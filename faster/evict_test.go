@@ -0,0 +1,41 @@
+package faster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvict(t *testing.T) {
+	f := New()
+	f.SetEvictAfter(50 * time.Millisecond)
+
+	f.Track("http", "GET /old").Done()
+	f.Track("http", "GET /old").Done()
+	var active = f.Track("http", "GET /active")
+	var before = f.TakeSnapshot()
+
+	// keep "GET /" alive while the others go stale
+	assert.Eventually(t, func() bool {
+		f.Track("http", "GET /").Done()
+		return f.TakeSnapshot().Get("http", "GET /old") == nil
+	}, time.Second, 10*time.Millisecond)
+
+	var snap = f.TakeSnapshot()
+	assert.NotNil(t, snap.Get("http", "GET /"))
+	assert.Equal(t, int32(1), snap.Get("http", "GET /active").Active())
+	assert.NotNil(t, before.Get("http", "GET /old")) // Snapshots aren't affected
+
+	// evicted keys start at 0 again (which is treated like a reset when comparing to older Snapshots)
+	f.Track("http", "GET /old").Done()
+	snap = f.TakeSnapshot()
+	assert.Equal(t, int64(1), snap.Get("http", "GET /old").Count())
+	assert.Equal(t, int64(1), snap.Get("http", "GET /old").Sub(before.Get("http", "GET /old")).Count())
+	assert.Equal(t, int64(1), snap.GetHistogram("http", "GET /old").Since(*before.GetHistogram("http", "GET /old")).Count())
+
+	active.Done()
+	f.SetEvictAfter(0)
+	time.Sleep(100 * time.Millisecond)
+	assert.NotNil(t, f.TakeSnapshot().Get("http", "GET /active"))
+}
@@ -4,24 +4,51 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mreithub/go-faster/faster/internal"
 )
 
-// TODO tracking execution time might cause performance issues (e.g. in virtualized environments gettimeofday() might be slow)
-//   if that turns out to be the case, deactivate Data.TotalNsec
+// Note: tracking execution time might cause performance issues (e.g. in virtualized environments gettimeofday() might be slow)
+//   if that turns out to be the case, use WithClock() with a cheaper time source
 
 // Faster -- A simple, go-style key-based reference counter that can be used for profiling your application (main class)
 type Faster struct {
-	tree       internal.RWTree
-	data       []data
-	histograms []Histogram
+	// number of tracking events dropped because evChannel was full (accessed atomically, 64-bit aligned - see DroppedEvents)
+	droppedTrack   int64
+	droppedDone    int64
+	droppedCounter int64
+	droppedGauge   int64
+	// droppedDone value at the time of the last sweepDropped() call (only accessed by the run() goroutine)
+	sweptDone int64
+	// number of Track() calls (accessed atomically, only counted if sampling is enabled - see WithSampling())
+	sampleCalls int64
+	// number of AlertRule callbacks dropped because alertCallbacks was full (accessed atomically)
+	droppedAlertCallbacks int64
+
+	tree internal.RWTree
+	// data points and histograms (by tree index)
+	store store
 
 	withHistograms bool
+	// clock -- source of all timestamps (see Clock)
+	clock Clock
 
 	// processed by the run() goroutine
 	evChannel chan internal.Event
+	// what to do with tracking events if evChannel is full
+	fullBufferPolicy FullBufferPolicy
+
+	// per-instance sampling (tracks one in sampleRate calls - see WithSampling())
+	sampleMode SampleMode
+	sampleRate int
+	// per-key sample rates (by pathKey(), see NewSampler() - only accessed by the run() goroutine)
+	sampleRates map[string]int
+	// all the Samplers created by NewSampler() (only accessed by the run() goroutine, see flushSamplers())
+	samplers []*Sampler
+	// records Go runtime metrics (nil unless enabled, only accessed by the run() goroutine - see SetRuntimeMetrics())
+	runtime *runtimeCollector
 
 	// calling TakeSnapshot() triggers an internal.EvSnapshot which in turn causes
 	// the run() goroutine to take one and push it here -- while it's not guaranteed
@@ -30,23 +57,88 @@ type Faster struct {
 	// change the results of each call
 	snapshotChannel chan *Snapshot
 
+	// slow call Exemplars (by tree index)
+	slowCalls          map[int]*exemplarRing
+	slowCallThresholds thresholdNode
+
+	// Trackers that haven't been Done() yet (only recorded if needed - see recordsActive(), only accessed by the run() goroutine)
+	active map[*Tracker]struct{}
+	// set by SetLongRunning() (only accessed by the run() goroutine)
+	longRunningEnabled bool
+	// incremented by each Reset() (see Tracker.generation, only accessed by the run() goroutine)
+	generation uint32
+	// response channel for LongRunning() (works the same way as snapshotChannel)
+	longRunningChannel chan []ActiveTracker
+	// if != 0, Track() will capture the creation stack of each Tracker (accessed atomically)
+	captureStacks int32
+
+	// keys idle for longer than this will be evicted (disabled if <= 0, only accessed by the run() goroutine)
+	evictAfter time.Duration
+	// time each key was last tracked (by tree index, only maintained while eviction is enabled)
+	lastUsed []time.Time
+
 	// periodic snapshots
 	history map[string]*History
 	// guards the history map
 	historyLock sync.Mutex
-	// indicates a History ticker expired (and expects to be sent a new Snapshot)
-	tickChan chan *History
+	// AlertRules (evaluated on History ticks)
+	alerts map[string]*alertState
+	// guards the alerts map (and their state)
+	alertLock sync.Mutex
+	// AlertRule callbacks (processed by the runAlertCallbacks() goroutine)
+	alertCallbacks chan func()
+
+	// closed once the run() goroutine exits (see Stop())
+	stopped  chan struct{}
+	stopOnce sync.Once
 
 	// StartTS -- timestamp of this Faster object's creation
 	StartTS time.Time
 }
 
 func (f *Faster) do(evType internal.EventType, path []string, took time.Duration) {
-	f.evChannel <- internal.Event{
+	f.send(internal.Event{
 		Type: evType,
 		Path: path,
 		Took: took,
+	})
+}
+
+// doCall -- tracks a finished call of path (standing for weight calls) using a single event
+//
+// Unlike a do(EvTrack)/do(EvDone) pair, the call can't be dropped halfway (which
+// would leave the key's active count off by one, see DropWhenFull)
+func (f *Faster) doCall(path []string, took time.Duration, weight int64) {
+	f.send(internal.Event{
+		Type:  internal.EvCall,
+		Path:  path,
+		Took:  took,
+		Value: int(weight - 1),
+	})
+}
+
+// doTracker -- like do(), but passing on the Tracker (allowing the run() goroutine to keep track of active Trackers)
+func (f *Faster) doTracker(evType internal.EventType, t *Tracker, took time.Duration) {
+	f.send(internal.Event{
+		Type:    evType,
+		Path:    t.path,
+		Took:    took,
+		Value:   int(t.weight - 1),
+		Tracker: t,
+	})
+}
+
+// send -- passes ev on to the run() goroutine (tracking events are dropped if the buffer is full and DropWhenFull is set)
+func (f *Faster) send(ev internal.Event) {
+	if f.fullBufferPolicy == DropWhenFull && ev.Type.IsTracking() {
+		select {
+		case f.evChannel <- ev:
+		default:
+			f.drop(&ev)
+		}
+		return
 	}
+	f.evChannel <- ev
 }
 
 // getCaller -- returns the given stack trace entry in the format we want it
@@ -114,24 +206,72 @@ func (f *Faster) SetTicker(name string, interval time.Duration, keep int) {
 	if ticker, ok := f.history[name]; ok {
 		// replacing/removing an existing ticker -> stop the old one
 		ticker.Stop()
+		delete(f.history, name)
 	}
 
 	if interval == 0 {
 		return
 	}
 
-	f.history[name] = NewHistory(name, interval, keep, f.tickChan)
+	f.history[name] = newHistory(name, interval, keep, f.clock, f.tick)
+}
+
+// historyTick -- asks the run() goroutine to push a new Snapshot to the given History (closing done afterwards)
+type historyTick struct {
+	history *History
+	done    chan struct{}
+}
+
+// tick -- pushes a new Snapshot to the given History (waiting for the run() goroutine to do so)
+//
+// Ticks go through evChannel (like all the other events), so the Snapshot will
+// include everything tracked before the tick
+func (f *Faster) tick(h *History) {
+	var done = make(chan struct{})
+	select {
+	case f.evChannel <- internal.Event{
+		Type: internal.EvHistoryTick,
+		Data: historyTick{history: h, done: done},
+	}:
+	case <-f.stopped:
+		return // ticks racing with Stop() would block forever
+	}
+
+	select {
+	case <-done:
+	case <-f.stopped:
+	}
 }
 
 // Track -- Tracks an instance of 'key'
+//
+// If sampling is enabled and the call wasn't sampled (see WithSampling()), the
+// returned Tracker doesn't record anything (see Tracker.Sampled()).
+// All of Tracker's methods can be called on nil.
 func (f *Faster) Track(key ...string) *Tracker {
-	f.do(internal.EvTrack, key, 0)
+	var weight int64 = 1
+	if f.sampleRate > 1 {
+		if weight = f.trackSampled(); weight == 0 {
+			return notSampled(f, key)
+		}
+	}
+	return f.track(key, weight)
+}
 
-	return &Tracker{
+// track -- creates a Tracker standing for weight calls of key
+func (f *Faster) track(key []string, weight int64) *Tracker {
+	var rc = &Tracker{
 		parent:  f,
 		path:    key,
-		startTS: time.Now(),
+		startTS: f.clock.Now(),
+		weight:  weight,
+	}
+	if atomic.LoadInt32(&f.captureStacks) != 0 {
+		rc.stack = captureStack()
 	}
+
+	f.doTracker(internal.EvTrack, rc, 0)
+	return rc
 }
 
 // TrackFn -- Tracks the calling function (using ["src", "pkgName", "typeName", "fn()"] as key - omitting typeName if empty)
@@ -141,39 +281,112 @@ func (f *Faster) TrackFn() *Tracker {
 }
 
 func (f *Faster) run() {
-	for {
-		select {
-		case msg := <-f.evChannel:
-			//log.Print("~~gofaster: ", msg)
-			switch msg.Type {
-			case internal.EvTrack:
-				f.onTrack(msg.Path)
-			case internal.EvDone:
-				f.onDone(msg.Path, msg.Took)
-			case internal.EvSnapshot:
-				var snap = f.takeSnapshot(time.Now())
-				f.snapshotChannel <- snap
-			case internal.EvReset:
-				f.onReset()
-			case internal.EvStop:
-				return // TODO stop this Faster instance safely
-			default:
-				panic("unsupported Faster event type")
+	// periodically triggers evict() (if enabled)
+	var evictTicker Ticker
+
+	for msg := range f.evChannel {
+		//log.Print("~~gofaster: ", msg)
+		switch msg.Type {
+		case internal.EvTrack:
+			if t, ok := msg.Tracker.(*Tracker); ok {
+				if atomic.LoadInt32(&t.doneDropped) != 0 {
+					break // its Done() event was dropped already -> ignore both
+				}
+				t.generation = f.generation
+				if f.recordsActive() {
+					f.active[t] = struct{}{}
+				}
+			}
+			f.onTrack(&msg)
+		case internal.EvDone:
+			var balanced = true
+			if t, ok := msg.Tracker.(*Tracker); ok {
+				// if the generations don't match, its EvTrack was dropped (or Reset() was called in between)
+				balanced = t.generation == f.generation
+				if f.recordsActive() {
+					delete(f.active, t)
+				}
+			}
+			var index = f.onDone(&msg, balanced)
+			if t, ok := msg.Tracker.(*Tracker); ok {
+				f.onSlowCall(index, t, msg.Took)
+			}
+		case internal.EvCall:
+			f.onDone(&msg, false) // there's no EvTrack -> doesn't affect the active count
+		case internal.EvCounterAdd, internal.EvGaugeSet, internal.EvGaugeAdd:
+			f.onMetric(&msg)
+		case internal.EvSetRuntimeMetrics:
+			f.setRuntimeMetrics(msg.Value == 1)
+		case internal.EvSetLongRunning:
+			f.setLongRunning(msg.Value == 1)
+		case internal.EvSnapshot:
+			f.sweepDropped()
+			f.flushSamplers()
+			f.collectRuntime()
+			var snap = f.takeSnapshot(f.clock.Now())
+			f.snapshotChannel <- snap
+		case internal.EvLongRunning:
+			f.sweepDropped()
+			f.longRunningChannel <- f.longRunning(f.clock.Now(), msg.Took)
+		case internal.EvSetSampleRate:
+			var sampler, _ = msg.Data.(*Sampler)
+			f.setSampleRate(msg.Path, msg.Value, sampler)
+		case internal.EvSetSlowCallThreshold:
+			f.slowCallThresholds.set(msg.Path, msg.Took)
+		case internal.EvSetSubtreeLimit:
+			f.tree.SetSubtreeLimit(msg.Value, msg.Path...)
+		case internal.EvSetLimit:
+			f.tree.Limit = msg.Value
+		case internal.EvSetFanOutLimit:
+			f.tree.SetFanOutLimit(msg.Value, msg.Path...)
+		case internal.EvSetEvictAfter:
+			if evictTicker != nil {
+				evictTicker.Stop()
+				evictTicker = nil
+			}
+			f.setEvictAfter(f.clock.Now(), msg.Took)
+			if msg.Took > 0 {
+				var interval = msg.Took / 2
+				if interval <= 0 {
+					interval = msg.Took
+				}
+				evictTicker = f.clock.NewTicker(interval, func(now time.Time) {
+					select {
+					case f.evChannel <- internal.Event{Type: internal.EvEvict, Data: now}:
+					case <-f.stopped:
+					}
+				})
+			}
+		case internal.EvEvict:
+			f.evict(msg.Data.(time.Time))
+		case internal.EvHistoryTick:
+			var tick = msg.Data.(historyTick)
+			f.sweepDropped()
+			f.flushSamplers()
+			f.collectRuntime()
+			var snap = f.takeSnapshot(f.clock.Now())
+			var prev = tick.history.last()
+			tick.history.push(snap)
+			f.evaluateAlerts(tick.history.Name, snap, prev)
+			close(tick.done)
+		case internal.EvReset:
+			f.onReset()
+		case internal.EvStop:
+			if evictTicker != nil {
+				evictTicker.Stop()
 			}
-		case history := <-f.tickChan:
-			//log.Print("tick: ", history)
-			var snap = f.takeSnapshot(time.Now())
-			history.push(snap)
+			close(f.alertCallbacks) // ends the runAlertCallbacks() goroutine
+			close(f.stopped)
+			return
+		default:
+			panic("unsupported Faster event type")
 		}
 	}
 }
 
 // getData -- returns a pointer to the internal.Data object with the given index (extending f.data if necessary)
 func (f *Faster) getData(index int) *data {
-	if index >= len(f.data) {
-		f.data = append(f.data, make([]data, index-len(f.data)+1)...)
-	}
-	return &f.data[index]
+	return f.store.getData(index)
 }
 
 // getDataForPath -- returns a pointer to the internal.Data object with the given path (or creates it if necessary)
@@ -186,29 +399,42 @@ func (f *Faster) getHistogram(index int) *Histogram {
 	if !f.withHistograms {
 		return nil
 	}
-	if index >= len(f.histograms) {
-		f.histograms = append(f.histograms, make([]Histogram, index-len(f.histograms)+1)...)
-	}
-	return &f.histograms[index]
+	return f.store.getHistogram(index)
 }
 
-func (f *Faster) onDone(path []string, took time.Duration) {
-	var index = f.tree.GetIndex(path...)
-
-	f.getData(index).Done(took)
-	if h := f.getHistogram(index); h != nil {
-		h.Add(took)
+// onDone -- updates the data (and histogram) of the given path, returns its index
+//
+// balanced is false if the matching EvTrack never made it (-> the active count won't be decremented)
+func (f *Faster) onDone(ev *internal.Event, balanced bool) int {
+	var index = f.tree.GetIndex(ev.Path...)
+	f.touch(index, ev)
+
+	var d = f.getData(index)
+	if !balanced {
+		d.active++ // compensate for Done()
+	}
+	d.Done(ev.Took, int64(1+ev.Value))
+	d.onAllocs(ev)
+	if h := f.getHistogram(index); h != nil && ev.Value >= 0 {
+		// weight 0: a Sampler call that flushSamplers() has already accounted for
+		h.Add(ev.Took)
 	}
+	return index
 }
 
 func (f *Faster) onReset() {
-	f.data = nil
-	f.histograms = nil
+	f.store = store{}
+	f.active = make(map[*Tracker]struct{})
+	f.generation++
+	f.slowCalls = make(map[int]*exemplarRing)
+	f.lastUsed = nil
 	f.tree.Reset()
 }
 
-func (f *Faster) onTrack(path []string) {
-	f.getDataForPath(path...).active++
+func (f *Faster) onTrack(ev *internal.Event) {
+	var index = f.tree.GetIndex(ev.Path...)
+	f.touch(index, ev)
+	f.getData(index).active++
 }
 
 // ListTickers -- returns the (currently registered) History tickers (taking periodic snapshots)
@@ -230,18 +456,26 @@ func (f *Faster) TakeSnapshot() *Snapshot {
 	return <-f.snapshotChannel
 }
 
-// takeSnapshot -- internal (-> thread-unsafe) method taking a deep copy of the current state
+// takeSnapshot -- internal (-> thread-unsafe) method taking a (copy-on-write) copy of the current state
 //
 // should only ever be called from within the run() goroutine
 func (f *Faster) takeSnapshot(now time.Time) *Snapshot {
 	var rc = Snapshot{
-		tree:       f.tree.Clone(),
-		data:       make([]data, len(f.data)),
-		histograms: make([]Histogram, len(f.histograms)),
-		TS:         now,
+		tree:      f.tree.Clone(),
+		store:     f.store.share(),
+		slowCalls: make(map[int][]Exemplar, len(f.slowCalls)),
+		dropped:   f.tree.Dropped(),
+		TS:        now,
+
+		droppedEvents: f.DroppedEvents(),
+		sampleRate:    f.sampleRate,
+	}
+	if len(f.sampleRates) > 0 {
+		rc.sampleRates = mergeSampleRates(nil, f.sampleRates)
+	}
+	for index, ring := range f.slowCalls {
+		rc.slowCalls[index] = ring.list()
 	}
-	copy(rc.data, f.data)
-	copy(rc.histograms, f.histograms)
 
 	return &rc
 }
@@ -251,32 +485,67 @@ func (f *Faster) Reset() {
 	f.do(internal.EvReset, nil, 0)
 }
 
-// SetLimit -- set a limit for Faster data points (i.e. tree nodes)
+// Stop -- stops this Faster instance's History tickers and goroutines (e.g. when it's no longer needed in tests)
 //
-// Everything exceeding that limit will end up in the root path "_overflow".
-// Set to <0 to disable (note that this might cause memory issues if used with unchecked input)
-// Defaults to 1000
-func (f *Faster) SetLimit(newLimit int) {
-	f.tree.Limit = newLimit
+// Events sent before are still processed (and pending AlertRule callbacks still
+// get called). Don't use the instance afterwards (calling Stop() again is fine).
+func (f *Faster) Stop() {
+	f.stopOnce.Do(func() {
+		for name := range f.ListTickers() {
+			f.SetTicker(name, 0, 0)
+		}
+		f.evChannel <- internal.Event{Type: internal.EvStop}
+		<-f.stopped
+	})
 }
 
 // New -- Construct a new root-level Faster instance
-func New(withHistograms bool) *Faster {
+//
+// Without options, it records histograms, stores up to 1000 keys and blocks
+// Track()/Done() callers while its event buffer (of 100 events) is full.
+func New(opts ...Option) *Faster {
+	var o = defaultOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	rc := &Faster{
-		withHistograms: withHistograms,
+		withHistograms: o.histogramType != NoHistograms,
+		clock:          o.clock,
 		tree: internal.RWTree{
-			Limit: 1000,
+			Limit: o.keyLimit,
 		},
 
-		evChannel:       make(chan internal.Event, 100),
-		snapshotChannel: make(chan *Snapshot, 5),
+		evChannel:        make(chan internal.Event, o.eventBuffer),
+		fullBufferPolicy: o.fullBufferPolicy,
+		sampleMode:       o.sampleMode,
+		sampleRate:       o.sampleRate,
+		snapshotChannel:  make(chan *Snapshot, 5),
 
-		tickChan: make(chan *History),
-		history:  make(map[string]*History),
-		StartTS:  time.Now(),
+		slowCalls:          make(map[int]*exemplarRing),
+		active:             make(map[*Tracker]struct{}),
+		longRunningEnabled: o.longRunning,
+		generation:         1,
+		longRunningChannel: make(chan []ActiveTracker, 5),
+
+		history: make(map[string]*History),
+		alerts:  make(map[string]*alertState),
+
+		alertCallbacks: make(chan func(), 100),
+		stopped:        make(chan struct{}),
+		StartTS:        o.clock.Now(),
 	}
 
 	go rc.run()
+	go rc.runAlertCallbacks()
+
+	if o.runtimeMetrics {
+		rc.SetRuntimeMetrics(true)
+	}
+
+	for _, t := range o.tickers {
+		rc.SetTicker(t.name, t.interval, t.keep)
+	}
 
 	return rc
 }
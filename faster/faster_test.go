@@ -8,7 +8,7 @@ import (
 )
 
 func TestBasics(t *testing.T) {
-	f := New(true)
+	f := New()
 
 	f.Track("hello").Done()
 
@@ -29,11 +29,11 @@ func TestBasics(t *testing.T) {
 	// final (current) state
 	assert.True(t, f.tree.Exists("hello"))
 	assert.True(t, f.tree.Exists("world"))
-	d := f.data[f.tree.GetIndex("hello")]
+	d := f.store.readData(f.tree.GetIndex("hello"))
 	assert.Equal(t, int32(0), d.Active())
 	assert.Equal(t, int64(2), d.Count())
 	assert.True(t, d.TotalTime() > 0)
-	d = f.data[f.tree.GetIndex("world")]
+	d = f.store.readData(f.tree.GetIndex("world"))
 	assert.Equal(t, int32(0), d.Active())
 	assert.Equal(t, int64(1), d.Count())
 	assert.True(t, d.TotalTime() >= 100000000)
@@ -48,7 +48,7 @@ func TestBasics(t *testing.T) {
 	assert.Equal(t, 0, snap1.tree.GetIndex())
 	assert.Equal(t, 1, snap1.tree.GetIndex("hello"))
 	assert.Equal(t, -1, snap1.tree.GetIndex("world"))
-	assert.Equal(t, 2, len(snap1.data))
+	assert.Equal(t, 1, len(snap1.store.data)) // (a single chunk)
 
 	// snap2: snap1 + Track('world'),  sleep(100ms)
 	assert.True(t, snap2.tree.Exists("hello"))
@@ -70,7 +70,7 @@ func TestBasics(t *testing.T) {
 }
 
 func TestReflection(t *testing.T) {
-	f := New(true)
+	f := New()
 
 	var tracker = f.TrackFn()
 	tracker.Done()
@@ -86,3 +86,66 @@ func TestReflection(t *testing.T) {
 	assert.Equal(t, []string{"src", "foo", "Bar", "*Func()"}, f.parseCaller("github.com/mreithub/foo.(*Bar).Func"))
 	assert.Equal(t, []string{"src", "foo", "Func()"}, f.parseCaller("github.com/mreithub/foo.Func"))
 }
+
+func TestSetTicker(t *testing.T) {
+	var f = New(WithHistograms(false))
+	f.SetTicker("sec", time.Second, 10)
+	f.SetTicker("min", time.Minute, 10)
+	assert.Len(t, f.ListTickers(), 2)
+
+	// replacing and removing tickers mustn't block
+	var old = f.ListTickers()["sec"]
+	f.SetTicker("sec", 2*time.Second, 10)
+	assert.Equal(t, 2*time.Second, f.ListTickers()["sec"].Interval())
+	f.SetTicker("min", 0, 0)
+	assert.Len(t, f.ListTickers(), 1)
+	old.Stop() // stopping twice is fine
+}
+
+func TestStop(t *testing.T) {
+	var f = New(WithHistograms(false), WithTicker("sec", time.Second, 10))
+	f.SetEvictAfter(time.Minute)
+	var called = make(chan Alert, 1)
+	f.SetAlert(AlertRule{Name: "foo", Ticker: "sec", Metric: &fakeMetric{values: []float64{1}}}, func(a Alert) { called <- a })
+	var snap = f.TakeSnapshot()
+	f.evaluateAlerts("sec", snap, snap)
+
+	f.Stop()
+	assert.Empty(t, f.ListTickers())
+	assert.True(t, (<-called).Firing, "pending callbacks are still called")
+	var _, ok = <-f.alertCallbacks
+	assert.False(t, ok, "the callback goroutine should've been stopped")
+
+	f.Stop() // stopping twice is fine
+}
+
+func TestNewChildPath(t *testing.T) {
+	var f = New()
+	defer f.Stop()
+
+	// a path with spare capacity (like the ones StartSpan() creates) mustn't be shared by the children
+	var path = make([]string, 1, 4)
+	path[0] = "foo"
+	var ref = f.Track(path...)
+	var a, b = ref.NewChild("a"), ref.NewChild("b")
+	assert.Equal(t, []string{"foo", "a"}, a.Path())
+	assert.Equal(t, []string{"foo", "b"}, b.Path())
+	a.Done()
+	b.Done()
+	ref.Done()
+}
+
+func TestZeroWeight(t *testing.T) {
+	var f = New()
+	defer f.Stop()
+
+	// Sampler.Track() returns weight 0 Trackers if flushSamplers() has already counted the call
+	var ref = f.track([]string{"foo"}, 0)
+	ref.NewChild("bar").Done()
+	ref.Done()
+
+	var snap = f.TakeSnapshot()
+	assert.Equal(t, int64(0), snap.Get("foo").Count())
+	assert.Equal(t, int64(0), snap.GetHistogram("foo").Count())
+	assert.Equal(t, int64(0), snap.GetHistogram("foo", "bar").Count())
+}
@@ -0,0 +1,11 @@
+//go:build go1.14
+// +build go1.14
+
+package fastertest
+
+import "testing"
+
+// cleanup -- calls fn once the test finishes
+func cleanup(t testing.TB, fn func()) {
+	t.Cleanup(fn)
+}
@@ -0,0 +1,9 @@
+//go:build !go1.14
+// +build !go1.14
+
+package fastertest
+
+import "testing"
+
+// cleanup -- testing.TB.Cleanup() isn't available before Go 1.14 (-> the Faster instance has to be stopped manually)
+func cleanup(t testing.TB, fn func()) {}
@@ -0,0 +1,226 @@
+// Package fastertest -- helpers for asserting on tracked performance in unit tests
+//
+//	func TestHandler(t *testing.T) {
+//		var f, clock = fastertest.NewWithClock(t)
+//
+//		var ref = f.Track("db", "query")
+//		clock.Advance(5 * time.Millisecond)
+//		ref.Done()
+//
+//		fastertest.AssertCalled(t, f, 1, "db", "query")
+//		fastertest.AssertMaxP99(t, f, 10*time.Millisecond, "db", "query")
+//		fastertest.AssertNoActive(t, f)
+//	}
+package fastertest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mreithub/go-faster/faster"
+)
+
+// Clock -- deterministic faster.Clock that only moves when told to (safe for concurrent use)
+//
+// Its Tickers fire synchronously while advancing the Clock (i.e. once Advance()
+// returns, the History snapshots for all the ticks in between have been taken).
+// Don't call Advance() or Set() from within a Ticker callback.
+type Clock struct {
+	lock    sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+// fakeTicker -- faster.Ticker returned by Clock.NewTicker()
+type fakeTicker struct {
+	clock    *Clock
+	interval time.Duration
+	next     time.Time
+	fn       func(now time.Time)
+}
+
+// Stop -- implements faster.Ticker
+func (t *fakeTicker) Stop() {
+	var c = t.clock
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for i, other := range c.tickers {
+		if other == t {
+			c.tickers = append(c.tickers[:i], c.tickers[i+1:]...)
+			break
+		}
+	}
+}
+
+// Now -- returns the Clock's current time
+func (c *Clock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// NewTicker -- implements faster.Clock (the Ticker fires while the Clock is advanced)
+func (c *Clock) NewTicker(interval time.Duration, fn func(now time.Time)) faster.Ticker {
+	if interval <= 0 {
+		panic("fastertest: non-positive interval for NewTicker")
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var rc = fakeTicker{
+		clock:    c,
+		interval: interval,
+		next:     c.now.Add(interval),
+		fn:       fn,
+	}
+	c.tickers = append(c.tickers, &rc)
+	return &rc
+}
+
+// Advance -- moves the Clock forward by d (firing all the ticks in between)
+func (c *Clock) Advance(d time.Duration) {
+	c.advanceTo(c.Now().Add(d))
+}
+
+// Set -- sets the Clock to the given time
+//
+// Moving forward fires all the ticks in between (see Advance()), moving backwards doesn't affect the Tickers
+func (c *Clock) Set(ts time.Time) {
+	c.lock.Lock()
+	if !ts.After(c.now) {
+		c.now = ts
+		c.lock.Unlock()
+		return
+	}
+	c.lock.Unlock()
+	c.advanceTo(ts)
+}
+
+// advanceTo -- moves the Clock forward tick by tick (calling the Tickers' callbacks without holding the lock)
+func (c *Clock) advanceTo(target time.Time) {
+	for {
+		c.lock.Lock()
+		var next *fakeTicker
+		for _, t := range c.tickers {
+			if !t.next.After(target) && (next == nil || t.next.Before(next.next)) {
+				next = t
+			}
+		}
+		if next == nil {
+			if target.After(c.now) {
+				c.now = target
+			}
+			c.lock.Unlock()
+			return
+		}
+
+		var ts = next.next
+		if ts.After(c.now) {
+			c.now = ts
+		}
+		next.next = ts.Add(next.interval)
+		c.lock.Unlock()
+
+		next.fn(ts)
+	}
+}
+
+// NewClock -- returns a Clock starting at the given time (or 2020-01-01 UTC if start is zero)
+func NewClock(start time.Time) *Clock {
+	if start.IsZero() {
+		// Trackers don't measure durations if their start time is zero
+		start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return &Clock{now: start}
+}
+
+// New -- returns an isolated Faster instance (with histograms, unless disabled by opts) for the given test
+//
+// It keeps track of active Trackers (see faster.WithLongRunning() and AssertNoActive()).
+// It will be stopped (along with its History tickers) when the test finishes (see faster.Stop()).
+// Before Go 1.14 (which added testing.TB.Cleanup()), call Stop() yourself.
+func New(t testing.TB, opts ...faster.Option) *faster.Faster {
+	return newFaster(t, opts)
+}
+
+// NewWithClock -- like New(), but the returned Faster instance uses a fake Clock
+//
+// Trackers will only see time pass when the Clock is advanced - so the durations
+// you assert on are exactly the ones you advanced the clock by.
+func NewWithClock(t testing.TB, opts ...faster.Option) (*faster.Faster, *Clock) {
+	var clock = NewClock(time.Time{})
+	opts = append(opts[:len(opts):len(opts)], faster.WithClock(clock))
+	return newFaster(t, opts), clock
+}
+
+func newFaster(t testing.TB, opts []faster.Option) *faster.Faster {
+	opts = append([]faster.Option{faster.WithLongRunning()}, opts...)
+	var f = faster.New(opts...)
+	cleanup(t, f.Stop)
+	return f
+}
+
+// keyName -- formats the key for assertion messages
+func keyName(key []string) string {
+	return fmt.Sprintf("'%s'", strings.Join(key, " | "))
+}
+
+// AssertCalled -- asserts that key was tracked (and Done()) exactly n times
+func AssertCalled(t testing.TB, f *faster.Faster, n int64, key ...string) bool {
+	t.Helper()
+	var count int64
+	if d := f.TakeSnapshot().Get(key...); d != nil {
+		count = d.Count()
+	}
+	if count != n {
+		t.Errorf("expected %s to be called %d times, got %d", keyName(key), n, count)
+		return false
+	}
+	return true
+}
+
+// AssertMaxP99 -- asserts that the 99th percentile of the key's durations doesn't exceed max
+//
+// Fails if the key wasn't tracked yet. Note that histogram buckets are powers of
+// two and percentiles are estimated using the upper bound of their bucket (e.g.
+// 5ms calls have a p99 of ~8.4ms)
+func AssertMaxP99(t testing.TB, f *faster.Faster, max time.Duration, key ...string) bool {
+	t.Helper()
+	var h = f.TakeSnapshot().GetHistogram(key...)
+	if h == nil || h.Count() == 0 {
+		t.Errorf("no durations recorded for %s (histograms enabled?)", keyName(key))
+		return false
+	}
+	if p99 := h.GetPercentile(99); p99 > max {
+		t.Errorf("expected p99 of %s to be at most %s, got %s", keyName(key), max, p99)
+		return false
+	}
+	return true
+}
+
+// AssertNoActive -- asserts that there are no Trackers that haven't been Done() (i.e. leaked Trackers)
+//
+// Only works for Faster instances that keep track of active Trackers (like the ones New()
+// returns, see faster.WithLongRunning()). Use Faster.SetCaptureStacks(true) to have the
+// failure message include where they were created
+func AssertNoActive(t testing.TB, f *faster.Faster) bool {
+	t.Helper()
+	var active = f.LongRunning(-1)
+	if len(active) == 0 {
+		return true
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "%d active Tracker(s):", len(active))
+	for _, tracker := range active {
+		fmt.Fprintf(&msg, "\n- %s (age: %s)", keyName(tracker.Path), tracker.Age)
+		if caller := tracker.Caller(); caller != "" {
+			fmt.Fprintf(&msg, ", created at %s", caller)
+		}
+	}
+	t.Error(msg.String())
+	return false
+}
@@ -0,0 +1,109 @@
+package fastertest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockT -- records assertion failures (instead of failing the actual test)
+type mockT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *mockT) Helper() {}
+func (t *mockT) Error(args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprint(args...))
+}
+func (t *mockT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestClock(t *testing.T) {
+	var c = NewClock(time.Time{})
+	var start = c.Now()
+	assert.False(t, start.IsZero())
+	c.Advance(time.Minute)
+	assert.Equal(t, time.Minute, c.Now().Sub(start))
+
+	var ts = time.Date(2019, 11, 3, 12, 0, 0, 0, time.UTC)
+	c.Set(ts)
+	assert.Equal(t, ts, c.Now())
+	assert.Equal(t, ts, NewClock(ts).Now())
+}
+
+func TestAssertions(t *testing.T) {
+	var f, clock = NewWithClock(t)
+	assert.Equal(t, clock.Now(), f.StartTS)
+
+	for i := 0; i < 3; i++ {
+		var ref = f.Track("db", "query")
+		clock.Advance(5 * time.Millisecond)
+		ref.Done()
+	}
+	var leaked = f.Track("http", "GET /")
+
+	var mock mockT
+	assert.True(t, AssertCalled(&mock, f, 3, "db", "query"))
+	assert.True(t, AssertCalled(&mock, f, 0, "unknown"))
+	assert.True(t, AssertMaxP99(&mock, f, 10*time.Millisecond, "db", "query"))
+	assert.Empty(t, mock.errors)
+
+	assert.False(t, AssertCalled(&mock, f, 2, "db", "query"))
+	assert.False(t, AssertMaxP99(&mock, f, 5*time.Millisecond, "db", "query"))
+	assert.False(t, AssertMaxP99(&mock, f, time.Millisecond, "http", "GET /"))
+	assert.False(t, AssertNoActive(&mock, f))
+	assert.Equal(t, []string{
+		"expected 'db | query' to be called 2 times, got 3",
+		"expected p99 of 'db | query' to be at most 5ms, got 8.388608ms",
+		"no durations recorded for 'http | GET /' (histograms enabled?)",
+		"1 active Tracker(s):\n- 'http | GET /' (age: 0s)",
+	}, mock.errors)
+
+	leaked.Done()
+	assert.True(t, AssertNoActive(t, f))
+}
+
+func TestNew(t *testing.T) {
+	var f = New(t)
+	f.SetTicker("sec", time.Second, 10)
+	f.Track("foo").Done()
+	AssertCalled(t, f, 1, "foo")
+	AssertNoActive(t, f)
+}
+
+func TestClockTicker(t *testing.T) {
+	var c = NewClock(time.Time{})
+	var start = c.Now()
+	var ticks []string
+	var tick = func(name string) func(time.Time) {
+		return func(now time.Time) {
+			ticks = append(ticks, fmt.Sprintf("%s@%s", name, now.Sub(start)))
+			assert.Equal(t, now, c.Now())
+		}
+	}
+
+	var a = c.NewTicker(2*time.Second, tick("a"))
+	c.NewTicker(3*time.Second, tick("b"))
+	c.Advance(time.Second)
+	assert.Empty(t, ticks)
+
+	c.Advance(5 * time.Second)
+	assert.Equal(t, []string{"a@2s", "b@3s", "a@4s", "a@6s", "b@6s"}, ticks)
+	assert.Equal(t, 6*time.Second, c.Now().Sub(start))
+
+	// moving backwards doesn't fire any ticks
+	ticks = nil
+	c.Set(start)
+	assert.Empty(t, ticks)
+
+	a.Stop()
+	a.Stop()
+	c.Set(start.Add(9 * time.Second))
+	assert.Equal(t, []string{"b@9s"}, ticks)
+
+	assert.Panics(t, func() { c.NewTicker(0, tick("c")) })
+}
@@ -0,0 +1,216 @@
+// Package grpcmw -- gRPC interceptors tracking unary and streaming calls using go-faster
+//
+// Calls are tracked as [prefix..., service, method] (prefix defaults to "grpc"). Each
+// call's status code is tracked as additional child key (e.g. "grpc", "pkg.Foo", "Bar", "_OK")
+// and stream messages are counted using the "_recv" and "_send" child keys.
+package grpcmw
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/mreithub/go-faster/faster"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Interceptors -- creates gRPC server and client interceptors for a Faster instance
+type Interceptors struct {
+	faster *faster.Faster
+	prefix []string
+}
+
+// New -- returns Interceptors that'll track gRPC calls in f (using the given key prefix - or "grpc" if omitted)
+//
+// If your application acts as both gRPC server and client (for the same methods),
+// consider using different prefixes for each of them (e.g. "grpc" and "grpc-client")
+func New(f *faster.Faster, prefix ...string) *Interceptors {
+	if len(prefix) == 0 {
+		prefix = []string{"grpc"}
+	}
+	return &Interceptors{
+		faster: f,
+		prefix: prefix,
+	}
+}
+
+// getPath -- turns a gRPC method name ("/pkg.Service/Method") into a go-faster path
+func (i *Interceptors) getPath(fullMethod string) []string {
+	var service, method = "_unknown", strings.TrimPrefix(fullMethod, "/")
+	if pos := strings.LastIndex(method, "/"); pos >= 0 {
+		service, method = method[:pos], method[pos+1:]
+	}
+
+	var rc = make([]string, 0, len(i.prefix)+2)
+	rc = append(rc, i.prefix...)
+	return append(rc, service, method)
+}
+
+// done -- tracks the status code of err as ("_"-prefixed) child of ref and calls ref.Done()
+func (i *Interceptors) done(ref *faster.Tracker, err error) {
+	ref.NewChild("_" + status.Code(err).String()).Done()
+	ref.Done()
+}
+
+// UnaryServer -- returns a grpc.UnaryServerInterceptor
+//
+// The handler's context will contain the call's Tracker (see faster.StartSpan())
+func (i *Interceptors) UnaryServer() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var ref = i.faster.Track(i.getPath(info.FullMethod)...)
+		var resp, err = handler(faster.WithTracker(ctx, ref), req)
+		i.done(ref, err)
+		return resp, err
+	}
+}
+
+// StreamServer -- returns a grpc.StreamServerInterceptor
+//
+// The stream's context will contain the call's Tracker (see faster.StartSpan())
+func (i *Interceptors) StreamServer() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		var path = i.getPath(info.FullMethod)
+		var ref = i.faster.Track(path...)
+		var err = handler(srv, &serverStream{
+			ServerStream: ss,
+			ctx:          faster.WithTracker(ss.Context(), ref),
+			messages:     newMessageCounter(i.faster, path),
+		})
+		i.done(ref, err)
+		return err
+	}
+}
+
+// UnaryClient -- returns a grpc.UnaryClientInterceptor
+func (i *Interceptors) UnaryClient() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var ref = i.faster.Track(i.getPath(method)...)
+		var err = invoker(ctx, method, req, reply, cc, opts...)
+		i.done(ref, err)
+		return err
+	}
+}
+
+// StreamClient -- returns a grpc.StreamClientInterceptor
+//
+// Client streams are considered done once RecvMsg() returns an error (io.EOF in case
+// of success) or - for streams without server-side streaming - after the first response
+// has been received. Streams that are abandoned by the caller (i.e. without reading
+// until an error is returned) will remain 'active'.
+func (i *Interceptors) StreamClient() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		var path = i.getPath(method)
+		var ref = i.faster.Track(path...)
+		var cs, err = streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			i.done(ref, err)
+			return nil, err
+		}
+
+		return &clientStream{
+			ClientStream:  cs,
+			interceptors:  i,
+			ref:           ref,
+			messages:      newMessageCounter(i.faster, path),
+			serverStreams: desc.ServerStreams,
+		}, nil
+	}
+}
+
+// serverStream -- wraps grpc.ServerStream (counting messages)
+type serverStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	messages messageCounter
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *serverStream) RecvMsg(m interface{}) error {
+	var err = s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.messages.recv()
+	}
+	return err
+}
+
+func (s *serverStream) SendMsg(m interface{}) error {
+	var err = s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.messages.send()
+	}
+	return err
+}
+
+// clientStream -- wraps grpc.ClientStream (counting messages and tracking the call until it's done)
+type clientStream struct {
+	grpc.ClientStream
+	interceptors  *Interceptors
+	ref           *faster.Tracker
+	messages      messageCounter
+	serverStreams bool
+
+	// SendMsg() and RecvMsg() may be called concurrently
+	finishOnce sync.Once
+}
+
+func (s *clientStream) finish(err error) {
+	s.finishOnce.Do(func() {
+		s.interceptors.done(s.ref, err)
+	})
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	var err = s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.messages.recv()
+		if !s.serverStreams {
+			s.finish(nil)
+		}
+	} else if err == io.EOF {
+		s.finish(nil)
+	} else {
+		s.finish(err)
+	}
+	return err
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	var err = s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.messages.send()
+	} else if err != io.EOF {
+		s.finish(err)
+	}
+	return err
+}
+
+// messageCounter -- counts stream messages (using the "_recv" and "_send" child keys of a call)
+//
+// only the counts of these keys are meaningful (their durations will be close to 0)
+type messageCounter struct {
+	faster   *faster.Faster
+	recvPath []string
+	sendPath []string
+}
+
+func newMessageCounter(f *faster.Faster, path []string) messageCounter {
+	var childPath = func(name string) []string {
+		var rc = make([]string, 0, len(path)+1)
+		rc = append(rc, path...)
+		return append(rc, name)
+	}
+
+	return messageCounter{
+		faster:   f,
+		recvPath: childPath("_recv"),
+		sendPath: childPath("_send"),
+	}
+}
+
+func (c *messageCounter) recv() { c.faster.Track(c.recvPath...).Done() }
+func (c *messageCounter) send() { c.faster.Track(c.sendPath...).Done() }
@@ -0,0 +1,136 @@
+package grpcmw
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mreithub/go-faster/faster"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const healthService = "grpc.health.v1.Health"
+
+// setup -- starts an in-process gRPC health server (returning a client connected to it)
+func setup(t *testing.T, server, client *faster.Faster) healthpb.HealthClient {
+	var listener = bufconn.Listen(1024 * 1024)
+	var srvInterceptors = New(server)
+	var srv = grpc.NewServer(
+		grpc.UnaryInterceptor(srvInterceptors.UnaryServer()),
+		grpc.StreamInterceptor(srvInterceptors.StreamServer()),
+	)
+	var healthSrv = health.NewServer()
+	healthSrv.SetServingStatus("foo", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthSrv)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	var clientInterceptors = New(client, "grpc-client")
+	var conn, err = grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(clientInterceptors.UnaryClient()),
+		grpc.WithStreamInterceptor(clientInterceptors.StreamClient()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return healthpb.NewHealthClient(conn)
+}
+
+// waitForCount -- waits (up to a second) for the given key to reach the expected count
+func waitForCount(f *faster.Faster, count int64, key ...string) faster.DataPoint {
+	var d faster.DataPoint
+	for i := 0; i < 100; i++ {
+		if d = f.TakeSnapshot().Get(key...); d != nil && d.Count() >= count {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return d
+}
+
+func TestGetPath(t *testing.T) {
+	var i = New(faster.New(faster.WithHistograms(false)))
+	assert.Equal(t, []string{"grpc", "pkg.Service", "Method"}, i.getPath("/pkg.Service/Method"))
+	assert.Equal(t, []string{"grpc", "_unknown", "invalid"}, i.getPath("invalid"))
+
+	i = New(faster.New(faster.WithHistograms(false)), "rpc", "server")
+	assert.Equal(t, []string{"rpc", "server", "pkg.Service", "Method"}, i.getPath("/pkg.Service/Method"))
+}
+
+func TestUnary(t *testing.T) {
+	var server, client = faster.New(faster.WithHistograms(false)), faster.New(faster.WithHistograms(false))
+	var hc = setup(t, server, client)
+
+	var _, err = hc.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "foo"})
+	assert.NoError(t, err)
+	_, err = hc.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "bar"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	for _, snap := range []*faster.Snapshot{server.TakeSnapshot(), client.TakeSnapshot()} {
+		var prefix = snap.Children()
+		if !assert.Len(t, prefix, 1) {
+			continue
+		}
+
+		var d = snap.Get(prefix[0], healthService, "Check")
+		assert.Equal(t, int64(2), d.Count())
+		assert.Equal(t, int32(0), d.Active())
+		assert.Equal(t, int64(1), snap.Get(prefix[0], healthService, "Check", "_OK").Count())
+		assert.Equal(t, int64(1), snap.Get(prefix[0], healthService, "Check", "_NotFound").Count())
+	}
+	assert.Equal(t, []string{"grpc"}, server.TakeSnapshot().Children())
+	assert.Equal(t, []string{"grpc-client"}, client.TakeSnapshot().Children())
+}
+
+func TestStream(t *testing.T) {
+	var server, client = faster.New(faster.WithHistograms(false)), faster.New(faster.WithHistograms(false))
+	var hc = setup(t, server, client)
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	var stream, err = hc.Watch(ctx, &healthpb.HealthCheckRequest{Service: "foo"})
+	assert.NoError(t, err)
+
+	var resp *healthpb.HealthCheckResponse
+	resp, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	var d = client.TakeSnapshot().Get("grpc-client", healthService, "Watch")
+	assert.Equal(t, int32(1), d.Active())
+	assert.Equal(t, int64(0), d.Count())
+
+	cancel()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+
+	// client
+	var snap = client.TakeSnapshot()
+	d = snap.Get("grpc-client", healthService, "Watch")
+	assert.Equal(t, int32(0), d.Active())
+	assert.Equal(t, int64(1), d.Count())
+	assert.Equal(t, int64(1), snap.Get("grpc-client", healthService, "Watch", "_Canceled").Count())
+	assert.Equal(t, int64(1), snap.Get("grpc-client", healthService, "Watch", "_recv").Count())
+	assert.Equal(t, int64(1), snap.Get("grpc-client", healthService, "Watch", "_send").Count())
+
+	// server (the handler may still be running at this point)
+	d = waitForCount(server, 1, "grpc", healthService, "Watch")
+	assert.Equal(t, int64(1), d.Count())
+	snap = server.TakeSnapshot()
+	assert.Equal(t, int64(1), snap.Get("grpc", healthService, "Watch", "_recv").Count())
+	assert.Equal(t, int64(1), snap.Get("grpc", healthService, "Watch", "_send").Count())
+	assert.Equal(t, int64(1), snap.Get("grpc", healthService, "Watch", "_Canceled").Count())
+}
@@ -1,6 +1,9 @@
 package faster
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 // Histogram -- Keeps track of time.Duration values and their distribution
 //
@@ -60,6 +63,19 @@ func (h *Histogram) Add(value time.Duration) {
 	h.buckets[bucket]++
 }
 
+// addN -- inserts the same value n times
+func (h *Histogram) addN(value time.Duration, n int64) {
+	h.sum += value * time.Duration(n)
+	h.count += n
+
+	var bucket = h.getBucket(value)
+	if v := int64(h.buckets[bucket]) + n; v < math.MaxInt32 {
+		h.buckets[bucket] = int32(v)
+	} else {
+		h.buckets[bucket] = math.MaxInt32 // buckets are only 32 bits wide
+	}
+}
+
 // Copy -- returns a copy of this Histogram instance
 func (h *Histogram) Copy() *Histogram {
 	var rc = *h
@@ -134,7 +150,14 @@ func (h *Histogram) GetValues() ([]time.Duration, []int32) {
 
 // Since -- subtracts the values of both histograms and returns the difference as new
 // object (will return nil if the histograms are incompatible (e.g. different resolution))
+//
+// If other has a higher count, the key was reset (or evicted) in between (-> a copy of h is returned)
 func (h *Histogram) Since(other Histogram) *Histogram {
+	if h.count < other.count {
+		var rc = *h
+		return &rc
+	}
+
 	var rc = Histogram{
 		count: h.count - other.count,
 		sum:   h.sum - other.sum,
@@ -147,6 +170,19 @@ func (h *Histogram) Since(other Histogram) *Histogram {
 	return &rc
 }
 
+// merge -- adds the values of other to this Histogram
+func (h *Histogram) merge(other *Histogram) {
+	h.count += other.count
+	h.sum += other.sum
+	for i, v := range other.buckets {
+		if sum := int64(h.buckets[i]) + int64(v); sum < math.MaxInt32 {
+			h.buckets[i] = int32(sum)
+		} else {
+			h.buckets[i] = math.MaxInt32 // buckets are only 32 bits wide
+		}
+	}
+}
+
 // minValues -- static list containing each bucket's lower bound
 var minValues = func() [64]time.Duration {
 	var rc [64]time.Duration
@@ -89,3 +89,14 @@ func TestHistogram(t *testing.T) {
 
 	//assert.EqualValues(t, []time.Duration{4 * NS, 512 * NS}, h.GetPercentiles(0, 100))
 }
+
+func TestMergeSaturates(t *testing.T) {
+	var h, other Histogram
+	h.addN(time.Millisecond, math.MaxInt32-1)
+	other.addN(time.Millisecond, 10)
+	h.merge(&other)
+
+	assert.Equal(t, int64(math.MaxInt32+9), h.Count())
+	var _, counts = h.GetValues()
+	assert.Equal(t, []int32{math.MaxInt32}, counts)
+}
@@ -10,8 +10,8 @@ import (
 //
 // All methods are thread safe
 type History struct {
-	ticker *time.Ticker
-	done   chan struct{}
+	// ticker -- triggers periodic snapshots (nil for manual Histories)
+	ticker Ticker
 
 	Name     string
 	Capacity int
@@ -43,6 +43,19 @@ func (h *History) First() *Snapshot {
 	return nil
 }
 
+// last -- returns the newest Snapshot entry of this History instance (or nil if empty)
+func (h *History) last() *Snapshot {
+	h.entryLock.Lock()
+	defer h.entryLock.Unlock()
+
+	if e := h.entries.Back(); e != nil {
+		if s, ok := e.Value.(*Snapshot); ok {
+			return s
+		}
+	}
+	return nil
+}
+
 // FirstTS -- convenience wrapper around First() returning that snapshot's timestamp (or a .IsZero() one)
 func (h *History) FirstTS() time.Time {
 	var rc time.Time
@@ -53,21 +66,36 @@ func (h *History) FirstTS() time.Time {
 }
 
 // GetData -- returns the TimeSeries for the given key
+//
+// Contains one Data point for each Snapshot (taken at Timestamps[i]). Snapshots
+// without the key (e.g. taken before it was first tracked or after it was
+// evicted) yield empty Data points. Returns an empty TimeSeries if none of them
+// has the key.
 func (h *History) GetData(path ...string) TimeSeries {
 	var snapshots = h.List()
 	var rc = TimeSeries{
-		Path:     path,
-		Data:     make([]DataPoint, 0, len(snapshots)),
-		StartTS:  h.FirstTS(),
-		Interval: h.Interval(),
+		Path:       path,
+		Data:       make([]DataPoint, 0, len(snapshots)),
+		StartTS:    h.FirstTS(),
+		Interval:   h.Interval(),
+		Timestamps: make([]time.Time, 0, len(snapshots)),
 	}
 
+	var found = false
 	for _, snapshot := range snapshots {
-		if d := snapshot.Get(path...); d != nil {
-			rc.Data = append(rc.Data, d)
+		var d = snapshot.Get(path...)
+		if d != nil {
+			found = true
+		} else {
+			d = &data{}
 		}
+		rc.Data = append(rc.Data, d)
+		rc.Timestamps = append(rc.Timestamps, snapshot.TS)
 	}
 
+	if !found {
+		rc.Data, rc.Timestamps = rc.Data[:0], rc.Timestamps[:0]
+	}
 	return rc
 }
 
@@ -102,6 +130,13 @@ func (h *History) List() Snapshots {
 	return rc
 }
 
+// Push -- adds a Snapshot to a History created by NewManualHistory() (thread safe)
+//
+// Snapshots are expected to be pushed in chronological order (about every Interval())
+func (h *History) Push(snapshot *Snapshot) {
+	h.push(snapshot)
+}
+
 // push -- Storing a new Snapshot in this History object - making sure we don't
 // exceed our Capacity) (thread safe)
 func (h *History) push(snapshot *Snapshot) {
@@ -117,10 +152,12 @@ func (h *History) push(snapshot *Snapshot) {
 	}
 }
 
-// Stop -- stop the underlying time.Ticker
+// Stop -- stop the underlying Ticker
 func (h *History) Stop() {
+	if h.ticker == nil {
+		return // manual History
+	}
 	h.ticker.Stop()
-	h.done <- struct{}{}
 }
 
 // NewHistory -- creates a History instance and initialize it as requested
@@ -129,8 +166,14 @@ func (h *History) Stop() {
 // (indicating to the underlying Faster instance that it should take another
 // snapshot and Push() it to this History instance)
 func NewHistory(name string, interval time.Duration, keep int, tickChannel chan *History) *History {
+	return newHistory(name, interval, keep, SystemClock, func(h *History) {
+		tickChannel <- h
+	})
+}
+
+// newHistory -- creates a History calling onTick initially and after each interval (using the given Clock)
+func newHistory(name string, interval time.Duration, keep int, clock Clock, onTick func(h *History)) *History {
 	var rc = History{
-		ticker:   time.NewTicker(interval),
 		Name:     name,
 		Capacity: keep,
 		interval: interval,
@@ -138,18 +181,22 @@ func NewHistory(name string, interval time.Duration, keep int, tickChannel chan
 	}
 
 	// get initial snapshot
-	tickChannel <- &rc
-
-	go func() {
-		for {
-			select {
-			case <-rc.ticker.C:
-				tickChannel <- &rc
-			case <-rc.done:
-				break
-			}
-		}
-	}()
+	onTick(&rc)
 
+	rc.ticker = clock.NewTicker(interval, func(time.Time) {
+		onTick(&rc)
+	})
 	return &rc
 }
+
+// NewManualHistory -- creates a History that doesn't take Snapshots by itself (use Push() to add them)
+//
+// Use this to keep track of Snapshots from other sources (e.g. ones received from other processes)
+func NewManualHistory(name string, interval time.Duration, keep int) *History {
+	return &History{
+		Name:     name,
+		Capacity: keep,
+		interval: interval,
+		entries:  list.New(),
+	}
+}
@@ -18,11 +18,65 @@ const (
 	EvTrack EventType = iota
 	// EvDone -- deref a ref counter (and updates the total count + time)
 	EvDone EventType = iota
+
+	// EvLongRunning -- lists Trackers that have been active for longer than Event.Took
+	EvLongRunning EventType = iota
+	// EvSetSampleRate -- sets the sample rate (one in Event.Value calls) of Event.Path
+	EvSetSampleRate EventType = iota
+	// EvSetSlowCallThreshold -- sets the slow call threshold (Event.Took) for Event.Path
+	EvSetSlowCallThreshold EventType = iota
+	// EvSetLimit -- sets the global key limit (Event.Value)
+	EvSetLimit EventType = iota
+	// EvSetSubtreeLimit -- sets the subtree limit (Event.Value) for Event.Path
+	EvSetSubtreeLimit EventType = iota
+	// EvSetFanOutLimit -- sets the fan-out limit (Event.Value) for Event.Path
+	EvSetFanOutLimit EventType = iota
+	// EvSetEvictAfter -- sets the idle duration (Event.Took) after which keys will be evicted
+	EvSetEvictAfter EventType = iota
+	// EvEvict -- evicts keys idle at Event.Data (time.Time, triggered periodically while eviction is enabled)
+	EvEvict EventType = iota
+	// EvCounterAdd -- adds Event.Delta to the Counter of Event.Path
+	EvCounterAdd EventType = iota
+	// EvGaugeSet -- sets the Gauge of Event.Path to Event.Gauge
+	EvGaugeSet EventType = iota
+	// EvGaugeAdd -- adds Event.Gauge to the Gauge of Event.Path
+	EvGaugeAdd EventType = iota
+	// EvCall -- a finished call of Event.Path (like an EvTrack + EvDone pair - but it can't be dropped halfway, Took and Value work like they do for EvDone)
+	EvCall EventType = iota
+	// EvSetRuntimeMetrics -- enables (Event.Value == 1) or disables recording Go runtime metrics
+	EvSetRuntimeMetrics EventType = iota
+	// EvHistoryTick -- pushes a new Snapshot to a History (Event.Data holds the History and a done channel)
+	EvHistoryTick EventType = iota
+	// EvSetLongRunning -- enables (Event.Value == 1) or disables keeping track of active Trackers (for LongRunning())
+	EvSetLongRunning EventType = iota
 )
 
+// IsTracking -- returns true for events caused by Trackers, Counters and Gauges (which may be dropped, see faster.DropWhenFull)
+func (t EventType) IsTracking() bool {
+	switch t {
+	case EvTrack, EvDone, EvCall, EvCounterAdd, EvGaugeSet, EvGaugeAdd:
+		return true
+	}
+	return false
+}
+
 // Event -- internal events
 type Event struct {
 	Type EventType
 	Path []string
 	Took time.Duration
+	// Value -- generic integer parameter (used by some of the EvSet* events)
+	//
+	// For EvDone, it's the number of additional (unsampled) calls the Tracker
+	// stands for (-1 if they were accounted for by another one, see faster.Sampler)
+	Value int
+	// Delta -- Counter increment (EvCounterAdd)
+	Delta int64
+	// Gauge -- Gauge value (or delta - EvGaugeSet and EvGaugeAdd)
+	Gauge float64
+
+	// Tracker -- the *faster.Tracker that caused this event (optional, used to keep track of active Trackers)
+	Tracker interface{}
+	// Data -- generic parameter (used by some events)
+	Data interface{}
 }
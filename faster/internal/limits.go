@@ -0,0 +1,63 @@
+package internal
+
+import "strings"
+
+// maxDroppedNames -- Dropped.Distinct will stop counting after reaching this value (to keep memory usage bounded)
+const maxDroppedNames = 1000
+
+// limitNode -- tree of per-subtree key limits (see RWTree.SetSubtreeLimit() and RWTree.SetFanOutLimit())
+type limitNode struct {
+	// maximum number of nodes in this subtree (<= 0: unlimited)
+	subtree int
+	// maximum number of children of each node in this subtree (0: inherit from parent, < 0: unlimited)
+	fanOut int
+
+	children map[string]*limitNode
+}
+
+// child -- returns the child with the given name (or nil if not found or n is nil)
+func (n *limitNode) child(name string) *limitNode {
+	if n == nil {
+		return nil
+	}
+	return n.children[name]
+}
+
+// getOrCreate -- returns the node with the given path (creating it if necessary)
+func (n *limitNode) getOrCreate(path []string) *limitNode {
+	for _, name := range path {
+		var child = n.children[name]
+		if child == nil {
+			if n.children == nil {
+				n.children = make(map[string]*limitNode)
+			}
+			child = &limitNode{}
+			n.children[name] = child
+		}
+		n = child
+	}
+	return n
+}
+
+// Dropped -- keeps track of keys that weren't created because of a limit (but ended up in an overflow node instead)
+type Dropped struct {
+	// Path -- path of the overflow node (e.g. ["http", "_other"] or ["_overflow"])
+	Path []string
+	// Distinct -- number of distinct keys that were dropped (stops counting at 1000)
+	Distinct int
+
+	names map[string]struct{}
+}
+
+// add -- records a dropped key
+func (d *Dropped) add(path []string) {
+	if len(d.names) >= maxDroppedNames {
+		return
+	}
+
+	var name = strings.Join(path, "/")
+	if _, ok := d.names[name]; !ok {
+		d.names[name] = struct{}{}
+		d.Distinct = len(d.names)
+	}
+}
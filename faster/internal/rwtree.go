@@ -1,5 +1,7 @@
 package internal
 
+import "sort"
+
 // RWTree -- read/write wrapper around the read-only TreeNode struct
 type RWTree struct {
 	curIndex int
@@ -12,10 +14,28 @@ type RWTree struct {
 	//
 	// set to <= 0 to disable
 	Limit int
+
+	// per-subtree limits (see SetSubtreeLimit() and SetFanOutLimit())
+	limits limitNode
+	// dropped keys (by the index of the overflow node they ended up in)
+	dropped map[int]*Dropped
+	// indexes of evicted nodes (sorted in descending order, will be reused by nextIndex())
+	free []int
+
+	// current generation - nodes of older generations are shared with Clone()d trees
+	// (and will be copied before being modified)
+	gen uint64
 }
 
-// nextIndex -- increments .curIndex, returning the old value
+// nextIndex -- returns the lowest reclaimed index (see Evict()) - or increments .curIndex, returning the old value
 func (t *RWTree) nextIndex(ignoreLimit bool) int {
+	if n := len(t.free); n > 0 {
+		if rc := t.free[n-1]; ignoreLimit || t.Limit <= 0 || rc < t.Limit {
+			t.free = t.free[:n-1]
+			return rc
+		}
+	}
+
 	var rc = t.curIndex
 	if ignoreLimit || t.Limit <= 0 || rc < t.Limit {
 		t.curIndex++
@@ -23,12 +43,65 @@ func (t *RWTree) nextIndex(ignoreLimit bool) int {
 	return rc
 }
 
-// Clone -- returns a read-only deep copy of the internal Tree structure
+// Clone -- returns a read-only copy of the internal Tree structure
+//
+// This doesn't actually copy anything, the returned nodes are shared until the
+// next modification (which copies the nodes on the path to the modified one)
 func (t *RWTree) Clone() *Tree {
-	var rc *Tree
-	if t.root != nil {
-		rc = t.root.cloneRec()
+	t.gen++
+	return t.root
+}
+
+// own -- returns a version of node that can be modified (copying it if it's shared with a Clone()d tree)
+func (t *RWTree) own(node *Tree) *Tree {
+	if node.gen == t.gen {
+		return node
+	}
+
+	var rc = Tree{
+		index:    node.index,
+		children: make(map[string]*Tree, len(node.children)+1),
+		size:     node.size,
+		gen:      t.gen,
+	}
+	for name, child := range node.children {
+		rc.children[name] = child
+	}
+	return &rc
+}
+
+// ownPath -- makes the nodes along the given (existing) path modifiable, returns them (starting with the root node)
+func (t *RWTree) ownPath(path []string) []*Tree {
+	var rc = make([]*Tree, 0, len(path)+1)
+	t.root = t.own(t.root)
+	rc = append(rc, t.root)
+
+	var node = t.root
+	for _, name := range path {
+		var child = t.own(node.children[name])
+		node.children[name] = child
+		rc = append(rc, child)
+		node = child
+	}
+	return rc
+}
+
+// addChild -- creates a new node below the given path (updating the size of all of its ancestors)
+func (t *RWTree) addChild(path []string, name string, index int) *Tree {
+	var nodes = t.ownPath(path)
+	for _, node := range nodes {
+		node.size++
+	}
+
+	var parent = nodes[len(nodes)-1]
+	if parent.children == nil {
+		parent.children = make(map[string]*Tree)
+	}
+	var rc = &Tree{
+		index: index,
+		gen:   t.gen,
 	}
+	parent.children[name] = rc
 	return rc
 }
 
@@ -44,60 +117,236 @@ func (t *RWTree) Exists(path ...string) bool {
 
 // GetIndex -- returns the sequential index assigned to the given path
 // (will create new tree nodes recursively)
+//
+// If creating a node would exceed one of the limits, the index of an overflow node
+// will be returned instead ('_other' below the node where a subtree or fan-out limit
+// was hit, or the root level '_overflow' node if the global Limit was reached)
 func (t *RWTree) GetIndex(path ...string) int {
 	if t.root == nil {
 		t.root = &Tree{
 			index: t.nextIndex(false), // 0
+			gen:   t.gen,
 		}
 	}
 
 	var curNode = t.root
-	for len(path) > 0 {
-		if curNode.children == nil {
-			curNode.children = make(map[string]*Tree)
-		}
-
+	for depth, name := range path {
 		var child *Tree
 		var ok bool
-		if child, ok = curNode.children[path[0]]; !ok {
+		if child, ok = curNode.children[name]; !ok {
+			if overflowDepth := t.checkLimits(path, depth); overflowDepth >= 0 {
+				return t.getOtherIndex(path, overflowDepth)
+			}
+
 			var nextIndex = t.nextIndex(false)
 			if t.Limit <= 0 || nextIndex < t.Limit {
-				child = &Tree{
-					index: nextIndex,
-				}
-				curNode.children[path[0]] = child
+				child = t.addChild(path[:depth], name, nextIndex)
 			} else {
-				return t.getOverflowIndex()
+				return t.getOverflowIndex(path[:depth+1])
 			}
 		}
 
 		curNode = child
-		path = path[1:]
 	}
 	return curNode.index
 }
 
+// checkLimits -- checks the subtree and fan-out limits before creating the node path[:depth+1]
+//
+// returns the depth of the node the new key's overflow node should be created at (or -1 if no limit was hit)
+func (t *RWTree) checkLimits(path []string, depth int) int {
+	var overflowDepth = -1
+	var node, limits = t.root, &t.limits
+	var fanOut = limits.fanOut
+	if limits.subtree > 0 && node.size >= limits.subtree {
+		overflowDepth = 0
+	}
+
+	for i := 0; i < depth; i++ {
+		node = node.children[path[i]]
+		if limits = limits.child(path[i]); limits != nil {
+			if limits.fanOut != 0 {
+				fanOut = limits.fanOut
+			}
+			if limits.subtree > 0 && node.size >= limits.subtree {
+				overflowDepth = i + 1
+			}
+		}
+	}
+
+	// fan-out limits apply to the new node's parent (-> the innermost level)
+	if fanOut > 0 {
+		var children = len(node.children)
+		if _, ok := node.children["_other"]; ok {
+			children--
+		}
+		if children >= fanOut {
+			overflowDepth = depth
+		}
+	}
+
+	return overflowDepth
+}
+
+// getOtherIndex -- returns the index of the '_other' child of path[:depth] (creates the node if necessary)
+//
+// path[depth:] is recorded as dropped key
+func (t *RWTree) getOtherIndex(path []string, depth int) int {
+	var parent = t.root
+	for _, name := range path[:depth] {
+		parent = parent.children[name]
+	}
+
+	var node *Tree
+	if node = parent.children["_other"]; node == nil {
+		node = t.addChild(path[:depth], "_other", t.nextIndex(true))
+	}
+
+	var otherPath = make([]string, 0, depth+1)
+	otherPath = append(otherPath, path[:depth]...)
+	t.recordDropped(node.index, append(otherPath, "_other"), path[depth:])
+	return node.index
+}
+
 // getOverflowIndex -- returns the index of the root tree entry '_overflow' (creates the node if neccessary)
-func (t *RWTree) getOverflowIndex() int {
+//
+// droppedPath is recorded as dropped key
+func (t *RWTree) getOverflowIndex(droppedPath []string) int {
 	if t.root == nil {
 		t.root = &Tree{
 			index: t.nextIndex(true), // 0
+			gen:   t.gen,
 		}
 	}
 
 	var node *Tree
 	if node = t.root.children["_overflow"]; node == nil {
+		// (not counted towards the root node's size)
+		t.root = t.own(t.root)
+		if t.root.children == nil {
+			t.root.children = make(map[string]*Tree)
+		}
 		node = &Tree{
 			index: t.nextIndex(true),
+			gen:   t.gen,
 		}
 		t.root.children["_overflow"] = node
 	}
 
+	t.recordDropped(node.index, []string{"_overflow"}, droppedPath)
 	return node.index
 }
 
+// recordDropped -- keeps track of keys that ended up in the overflow node with the given index
+func (t *RWTree) recordDropped(index int, overflowPath []string, droppedPath []string) {
+	if t.dropped == nil {
+		t.dropped = make(map[int]*Dropped)
+	}
+
+	var d = t.dropped[index]
+	if d == nil {
+		d = &Dropped{
+			Path:  overflowPath,
+			names: make(map[string]struct{}),
+		}
+		t.dropped[index] = d
+	}
+	d.add(droppedPath)
+}
+
+// Dropped -- returns the number of dropped keys for each overflow node (sorted by path)
+func (t *RWTree) Dropped() []Dropped {
+	var rc = make([]Dropped, 0, len(t.dropped))
+	for _, d := range t.dropped {
+		rc = append(rc, Dropped{
+			Path:     d.Path,
+			Distinct: d.Distinct,
+		})
+	}
+
+	sort.Slice(rc, func(i, j int) bool {
+		var a, b = rc[i].Path, rc[j].Path
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return rc
+}
+
+// SetSubtreeLimit -- limits the number of nodes below the given path
+//
+// once that limit is reached, new keys in that subtree will end up in its '_other' child.
+// Set to <= 0 to disable
+func (t *RWTree) SetSubtreeLimit(limit int, path ...string) {
+	t.limits.getOrCreate(path).subtree = limit
+}
+
+// SetFanOutLimit -- limits the number of children of each node in the given subtree
+//
+// once a node has that many children, new keys will end up in its '_other' child.
+// The most specific fan-out limit applies - set to 0 to remove it again (or to < 0
+// to disable fan-out limits for the given subtree)
+func (t *RWTree) SetFanOutLimit(limit int, path ...string) {
+	t.limits.getOrCreate(path).fanOut = limit
+}
+
+// Evict -- removes all leaf nodes for which canEvict() returns true (recursively, i.e.
+// parents whose children were all evicted will be checked as well)
+//
+// The root node is never evicted. Returns the indexes of the removed nodes (they
+// will be reused for new nodes)
+func (t *RWTree) Evict(canEvict func(index int) bool) []int {
+	if t.root == nil {
+		return nil
+	}
+
+	var rc []int
+	t.root, _ = t.evictRec(t.root, canEvict, &rc)
+	if len(rc) > 0 {
+		for _, index := range rc {
+			delete(t.dropped, index)
+		}
+		t.free = append(t.free, rc...)
+		sort.Sort(sort.Reverse(sort.IntSlice(t.free)))
+	}
+	return rc
+}
+
+// evictRec -- evicts node's descendants (appending their indexes to evicted)
+//
+// returns the (possibly copied) node and the number of removed nodes
+func (t *RWTree) evictRec(node *Tree, canEvict func(index int) bool, evicted *[]int) (*Tree, int) {
+	var removed = 0
+	for name, child := range node.children {
+		var newChild, childRemoved = t.evictRec(child, canEvict, evicted)
+		if len(newChild.children) == 0 && canEvict(newChild.index) {
+			node = t.own(node)
+			delete(node.children, name)
+			*evicted = append(*evicted, newChild.index)
+			removed += childRemoved + 1
+		} else {
+			if newChild != child {
+				node = t.own(node)
+				node.children[name] = newChild
+			}
+			removed += childRemoved
+		}
+	}
+
+	if removed > 0 {
+		node = t.own(node)
+		node.size -= removed
+	}
+	return node, removed
+}
+
 // Reset -- removes all nodes and resets the sequential index to 0
 func (t *RWTree) Reset() {
 	t.root = nil
 	t.curIndex = 0
+	t.dropped = nil
+	t.free = nil
 }
@@ -52,3 +52,132 @@ func TestLimit(t *testing.T) {
 	assert.False(t, tree.Exists("http", "GET /favicon.ico"))
 	assert.False(t, tree.Exists("https"))
 }
+
+func TestFanOutLimit(t *testing.T) {
+	var tree RWTree
+	tree.SetFanOutLimit(2, "http")
+
+	assert.Equal(t, 2, tree.GetIndex("http", "GET /"))   // 1 2
+	assert.Equal(t, 3, tree.GetIndex("http", "GET /a"))  // 1 3
+	assert.Equal(t, 4, tree.GetIndex("http", "GET /b"))  // 1 4(_other)
+	assert.Equal(t, 4, tree.GetIndex("http", "GET /c"))  // 1 4(_other)
+	assert.Equal(t, 2, tree.GetIndex("http", "GET /"))   // existing keys still work
+	assert.Equal(t, 6, tree.GetIndex("https", "GET /b")) // 5 6 (not affected)
+
+	// the limit applies to every level below "http"
+	assert.Equal(t, 7, tree.GetIndex("http", "GET /", "a"))
+	assert.Equal(t, 8, tree.GetIndex("http", "GET /", "b"))
+	assert.Equal(t, 9, tree.GetIndex("http", "GET /", "c", "d")) // http/GET //_other
+
+	// ... unless overridden
+	tree.SetFanOutLimit(-1, "http", "GET /a")
+	for i := 0; i < 5; i++ {
+		assert.NotEqual(t, 4, tree.GetIndex("http", "GET /a", string(rune('a'+i))))
+	}
+	assert.Len(t, tree.root.children["http"].children["GET /a"].children, 5)
+
+	assert.True(t, tree.Exists("http", "_other"))
+	assert.True(t, tree.Exists("http", "GET /", "_other"))
+	assert.False(t, tree.Exists("http", "GET /b"))
+	assert.Equal(t, []Dropped{
+		{Path: []string{"http", "GET /", "_other"}, Distinct: 1},
+		{Path: []string{"http", "_other"}, Distinct: 2},
+	}, tree.Dropped())
+}
+
+func TestSubtreeLimit(t *testing.T) {
+	var tree RWTree
+	tree.SetSubtreeLimit(3, "db")
+
+	assert.Equal(t, 3, tree.GetIndex("db", "users", "SELECT")) // 1 2 3
+	assert.Equal(t, 4, tree.GetIndex("db", "users", "INSERT")) // 1 2 4 (-> subtree size: 3)
+	assert.Equal(t, 5, tree.GetIndex("db", "orders"))          // 1 5(_other)
+	assert.Equal(t, 5, tree.GetIndex("db", "users", "UPDATE")) // 1 5(_other)
+	assert.Equal(t, 5, tree.GetIndex("db", "orders", "SELECT"))
+	assert.Equal(t, 7, tree.GetIndex("http", "GET /")) // 6 7 (not affected)
+
+	assert.Equal(t, []Dropped{
+		{Path: []string{"db", "_other"}, Distinct: 3},
+	}, tree.Dropped())
+
+	// the global limit is still applied (and tracks dropped keys too)
+	tree.Limit = 9
+	assert.Equal(t, 8, tree.GetIndex("https"))
+	assert.Equal(t, 9, tree.GetIndex("ftp"))
+	assert.Equal(t, 9, tree.GetIndex("gopher"))
+	assert.Equal(t, []Dropped{
+		{Path: []string{"_overflow"}, Distinct: 2},
+		{Path: []string{"db", "_other"}, Distinct: 3},
+	}, tree.Dropped())
+
+	tree.Reset()
+	assert.Empty(t, tree.Dropped())
+	assert.Equal(t, 3, tree.GetIndex("db", "users", "SELECT"))
+}
+
+func TestEvict(t *testing.T) {
+	var tree = RWTree{Limit: 6}
+	tree.SetSubtreeLimit(3, "http")
+
+	assert.Equal(t, 2, tree.GetIndex("http", "GET /"))      // 1 2
+	assert.Equal(t, 3, tree.GetIndex("http", "GET /old"))   // 1 3
+	assert.Equal(t, 4, tree.GetIndex("http", "GET /older")) // 1 4
+	assert.Equal(t, 5, tree.GetIndex("http", "GET /new"))   // 1 5(_other)
+	assert.Equal(t, 4, tree.root.children["http"].size)
+
+	var evicted = tree.Evict(func(index int) bool {
+		return index == 3 || index == 4 || index == 5
+	})
+	assert.ElementsMatch(t, []int{3, 4, 5}, evicted)
+	assert.False(t, tree.Exists("http", "GET /old"))
+	assert.False(t, tree.Exists("http", "_other"))
+	assert.Equal(t, 1, tree.root.children["http"].size)
+	assert.Empty(t, tree.Dropped())
+
+	// evicted indexes get reused (lowest first)
+	assert.Equal(t, 3, tree.GetIndex("http", "GET /new"))
+	assert.Equal(t, 4, tree.GetIndex("http", "GET /newer"))
+	assert.Equal(t, 5, tree.GetIndex("http", "_other", "foo"))
+
+	// parents are only evicted once all their children are gone
+	evicted = tree.Evict(func(index int) bool {
+		return index != 2
+	})
+	assert.ElementsMatch(t, []int{3, 4, 5}, evicted)
+	assert.True(t, tree.Exists("http", "GET /"))
+
+	evicted = tree.Evict(func(index int) bool { return true })
+	assert.ElementsMatch(t, []int{1, 2}, evicted)
+	assert.Equal(t, 0, tree.root.size)
+	assert.Equal(t, 2, tree.GetIndex("https", "GET /")) // 1 2
+}
+
+// TestClone -- Clone()d trees share their nodes (but mustn't be affected by later changes)
+func TestClone(t *testing.T) {
+	var tree RWTree
+	tree.SetSubtreeLimit(2, "http")
+	tree.GetIndex("http", "GET /")
+	tree.GetIndex("db", "query")
+
+	var clone = tree.Clone()
+	assert.Equal(t, clone, tree.root)
+
+	tree.GetIndex("http", "POST /")
+	tree.GetIndex("http", "PUT /") // -> http/_other
+	assert.True(t, tree.Exists("http", "POST /"))
+	assert.True(t, tree.Exists("http", "_other"))
+	assert.False(t, clone.Exists("http", "POST /"))
+	assert.False(t, clone.Exists("http", "_other"))
+	assert.Len(t, clone.Children("http"), 1)
+
+	// unmodified subtrees are still shared
+	assert.True(t, clone.getNode("db") == tree.root.getNode("db"))
+	assert.False(t, clone.getNode("http") == tree.root.getNode("http"))
+
+	var clone2 = tree.Clone()
+	tree.Evict(func(index int) bool { return true })
+	assert.False(t, tree.Exists("http"))
+	assert.True(t, clone2.Exists("http", "POST /"))
+	assert.True(t, clone2.Exists("db", "query"))
+	assert.Equal(t, 6, clone2.size)
+}
@@ -6,18 +6,10 @@ package internal
 type Tree struct {
 	index    int
 	children map[string]*Tree
-}
-
-func (n *Tree) cloneRec() *Tree {
-	var rc = Tree{
-		index:    n.index,
-		children: make(map[string]*Tree, len(n.children)),
-	}
-
-	for key, child := range n.children {
-		rc.children[key] = child.cloneRec()
-	}
-	return &rc
+	// number of descendants (used to enforce subtree limits)
+	size int
+	// generation the node was created in (see RWTree.own())
+	gen uint64
 }
 
 // getNode -- recursively traverses the tree to find the node with the given path (returns nil if not found)
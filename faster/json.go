@@ -0,0 +1,103 @@
+package faster
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// SnapshotJSON -- JSON representation of a Snapshot (see Snapshot.MarshalJSON())
+type SnapshotJSON struct {
+	TS   time.Time `json:"ts"`
+	Keys []KeyJSON `json:"keys"`
+	// DroppedEvents -- only set if tracking events were dropped (see Snapshot.DroppedEvents())
+	DroppedEvents *DroppedEvents `json:"droppedEvents,omitempty"`
+	// SampleRate -- only set if the Faster instance was sampled (see WithSampling())
+	SampleRate int `json:"sampleRate,omitempty"`
+}
+
+// KeyJSON -- JSON representation of a single key's data (durations are in nanoseconds)
+type KeyJSON struct {
+	Path      []string      `json:"path"`
+	Active    int32         `json:"active"`
+	Count     int64         `json:"count"`
+	TotalTime time.Duration `json:"totalNS"`
+	Average   time.Duration `json:"avgNS"`
+
+	// percentiles (only set if histograms are enabled)
+	P50 time.Duration `json:"p50NS,omitempty"`
+	P90 time.Duration `json:"p90NS,omitempty"`
+	P99 time.Duration `json:"p99NS,omitempty"`
+
+	// Counter -- only set for keys with a Counter (see Faster.Counter())
+	Counter *int64 `json:"counter,omitempty"`
+	// Gauge -- only set for keys with a Gauge (see Faster.Gauge())
+	Gauge *float64 `json:"gauge,omitempty"`
+
+	// heap allocations (only set for keys tracked with Faster.TrackAlloc())
+	AllocCalls int64 `json:"allocCalls,omitempty"`
+	Allocs     int64 `json:"allocs,omitempty"`
+	AllocBytes int64 `json:"allocBytes,omitempty"`
+
+	// SampleRate -- only set if the key was sampled (one in SampleRate calls was tracked, see Snapshot.SampleRate())
+	SampleRate int `json:"sampleRate,omitempty"`
+}
+
+// JSON -- returns the JSON representation of this Snapshot (containing all keys with data - sorted by path)
+func (s *Snapshot) JSON() SnapshotJSON {
+	var rc = SnapshotJSON{
+		TS:   s.TS,
+		Keys: []KeyJSON{},
+	}
+	if s.sampleRate > 1 {
+		rc.SampleRate = s.sampleRate
+	}
+	if s.droppedEvents.Total() > 0 {
+		var dropped = s.droppedEvents
+		rc.DroppedEvents = &dropped
+	}
+
+	s.walk(func(path []string) {
+		var d = s.getData(path)
+		if d == nil || (d.active == 0 && d.count == 0 && d.kind == 0) {
+			return
+		}
+
+		var key = KeyJSON{
+			Path:      path,
+			Active:    d.active,
+			Count:     d.count,
+			TotalTime: d.totalTime,
+			Average:   d.Average(),
+		}
+		if d.kind&kindCounter != 0 {
+			var counter = d.counter
+			key.Counter = &counter
+		}
+		if d.kind&kindGauge != 0 {
+			var gauge = d.gauge
+			key.Gauge = &gauge
+		}
+		if d.kind&kindAllocs != 0 {
+			key.AllocCalls, key.Allocs, key.AllocBytes = d.allocCalls, d.allocs, d.allocBytes
+		}
+		if n := s.SampleRate(path...); n > 1 {
+			key.SampleRate = n
+		}
+		if h := s.getHistogram(path); h != nil && h.count > 0 {
+			var p = h.GetPercentiles(50, 90, 99)
+			key.P50, key.P90, key.P99 = p[0], p[1], p[2]
+		}
+		rc.Keys = append(rc.Keys, key)
+	})
+
+	sort.Slice(rc.Keys, func(i, j int) bool {
+		return pathKey(rc.Keys[i].Path) < pathKey(rc.Keys[j].Path)
+	})
+	return rc
+}
+
+// MarshalJSON -- implements json.Marshaler (see SnapshotJSON)
+func (s *Snapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.JSON())
+}
@@ -0,0 +1,54 @@
+package faster
+
+import "github.com/mreithub/go-faster/faster/internal"
+
+// DroppedKeys -- number of distinct keys that ended up in an overflow node (see Snapshot.DroppedKeys())
+type DroppedKeys struct {
+	// Path -- the overflow node's path (e.g. ["http", "_other"] or ["_overflow"])
+	Path []string `json:"path"`
+	// Distinct -- number of distinct keys that were dropped (stops counting at 1000)
+	Distinct int `json:"distinct"`
+}
+
+// SetSubtreeLimit -- limits the number of keys below the given one
+//
+// Once the limit is reached, new keys in that subtree will be tracked as
+// <key>/_other (e.g. SetSubtreeLimit(100, "http") will track the 101st distinct
+// HTTP route as "http", "_other"). Set to <= 0 to remove the limit again.
+//
+// Note that the global limit (see SetLimit()) still applies.
+func (f *Faster) SetSubtreeLimit(limit int, key ...string) {
+	f.evChannel <- internal.Event{
+		Type:  internal.EvSetSubtreeLimit,
+		Path:  key,
+		Value: limit,
+	}
+}
+
+// SetFanOutLimit -- limits the number of direct children of the given key (and its descendants)
+//
+// Once a key has that many children, new ones will be tracked as its "_other"
+// child instead. The most specific fan-out limit applies (i.e. descendants can
+// override it). Set to 0 to remove the key's limit again (inheriting the one of
+// its closest ancestor, if any) or to < 0 to disable fan-out limits for its
+// subtree (even if an ancestor has one).
+func (f *Faster) SetFanOutLimit(limit int, key ...string) {
+	f.evChannel <- internal.Event{
+		Type:  internal.EvSetFanOutLimit,
+		Path:  key,
+		Value: limit,
+	}
+}
+
+// DroppedKeys -- returns the number of distinct keys that didn't get their own node
+// (because of one of the limits), for each overflow node (sorted by path)
+func (s *Snapshot) DroppedKeys() []DroppedKeys {
+	var rc = make([]DroppedKeys, len(s.dropped))
+	for i, d := range s.dropped {
+		rc[i] = DroppedKeys{
+			Path:     d.Path,
+			Distinct: d.Distinct,
+		}
+	}
+	return rc
+}
@@ -0,0 +1,51 @@
+package faster
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyLimits(t *testing.T) {
+	f := New(WithHistograms(false))
+	f.SetFanOutLimit(10, "http")
+	f.SetSubtreeLimit(5, "db")
+
+	for i := 0; i < 20; i++ {
+		f.Track("http", fmt.Sprintf("GET /user/%d", i)).Done()
+		f.Track("db", "query", fmt.Sprint(i)).Done()
+	}
+
+	var snap = f.TakeSnapshot()
+	assert.Len(t, snap.Children("http"), 11)
+	assert.Equal(t, int64(10), snap.Get("http", "_other").Count())
+	assert.Equal(t, int64(1), snap.Get("http", "GET /user/9").Count())
+	assert.Nil(t, snap.Get("http", "GET /user/10"))
+
+	assert.ElementsMatch(t, []string{"query", "_other"}, snap.Children("db"))
+	assert.Equal(t, int64(16), snap.Get("db", "_other").Count())
+	assert.Nil(t, snap.Get("_overflow"))
+
+	assert.Equal(t, []DroppedKeys{
+		{Path: []string{"db", "_other"}, Distinct: 16},
+		{Path: []string{"http", "_other"}, Distinct: 10},
+	}, snap.DroppedKeys())
+}
+
+func TestFanOutLimitOverrides(t *testing.T) {
+	f := New(WithHistograms(false))
+	f.SetFanOutLimit(2, "http")
+	f.SetFanOutLimit(-1, "http", "unlimited") // overrides the "http" limit
+	f.SetFanOutLimit(5, "http", "inherited")
+	f.SetFanOutLimit(0, "http", "inherited") // removed again -> inherits the "http" limit
+
+	for i := 0; i < 4; i++ {
+		f.Track("http", "unlimited", fmt.Sprint(i)).Done()
+		f.Track("http", "inherited", fmt.Sprint(i)).Done()
+	}
+
+	var snap = f.TakeSnapshot()
+	assert.Len(t, snap.Children("http", "unlimited"), 4)
+	assert.ElementsMatch(t, []string{"0", "1", "_other"}, snap.Children("http", "inherited"))
+}
@@ -0,0 +1,166 @@
+package faster
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mreithub/go-faster/faster/internal"
+)
+
+// maxStackDepth -- maximum number of stack frames captured for each Tracker (if enabled)
+const maxStackDepth = 32
+
+// pkgPrefix -- function name prefix of this package (used to skip go-faster's own stack frames)
+var pkgPrefix = func() string {
+	var name = runtime.FuncForPC(reflect.ValueOf(New).Pointer()).Name()
+	return name[:strings.LastIndex(name, ".")+1]
+}()
+
+// ActiveTracker -- info on a Tracker that hasn't been Done() yet (see Faster.LongRunning())
+type ActiveTracker struct {
+	Path    []string
+	StartTS time.Time
+	// Age -- time since StartTS (at the time Faster.LongRunning() was called)
+	Age time.Duration
+
+	// Stack -- the Tracker's creation stack (only set if enabled - see Faster.SetCaptureStacks())
+	Stack []runtime.Frame
+
+	// unresolved Stack (resolving is done outside the run() goroutine)
+	stack []uintptr
+}
+
+// Caller -- returns the Tracker's creation site ("file:line" - or "" if unknown)
+func (t *ActiveTracker) Caller() string {
+	if len(t.Stack) == 0 {
+		return ""
+	}
+	var frame = t.Stack[0]
+	return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+}
+
+// StackString -- formats Stack similar to the stack traces in Go's panic messages
+func (t *ActiveTracker) StackString() string {
+	var rc strings.Builder
+	for _, frame := range t.Stack {
+		fmt.Fprintf(&rc, "%s()\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+	}
+	return rc.String()
+}
+
+// captureStack -- returns the program counters of the current goroutine's stack (see ActiveTracker.Stack)
+func captureStack() []uintptr {
+	var pc = make([]uintptr, maxStackDepth)
+	var n = runtime.Callers(3, pc) // skip runtime.Callers(), captureStack() and its caller
+	return pc[:n]
+}
+
+// resolveStack -- resolves the given program counters to stack frames (skipping go-faster's own functions)
+func resolveStack(pc []uintptr) []runtime.Frame {
+	if len(pc) == 0 {
+		return nil
+	}
+
+	var rc []runtime.Frame
+	var frames = runtime.CallersFrames(pc)
+	for {
+		var frame, more = frames.Next()
+		var internal = strings.HasPrefix(frame.Function, pkgPrefix) && !strings.HasSuffix(frame.File, "_test.go")
+		if !internal || len(rc) > 0 {
+			rc = append(rc, frame)
+		}
+		if !more {
+			break
+		}
+	}
+	return rc
+}
+
+// SetCaptureStacks -- enables (or disables) capturing the creation stack of each Tracker
+//
+// Stacks are reported by LongRunning(). Note that capturing stacks is relatively
+// expensive (and therefore disabled by default) - and will only affect Trackers
+// created after enabling it.
+func (f *Faster) SetCaptureStacks(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&f.captureStacks, value)
+}
+
+// WithLongRunning -- keeps track of active Trackers (see SetLongRunning())
+func WithLongRunning() Option {
+	return func(o *options) { o.longRunning = true }
+}
+
+// SetLongRunning -- enables (or disables) keeping track of active Trackers (required by LongRunning())
+//
+// Disabled by default (as it adds a map insert and delete to each Track()/Done()
+// pair). Only Trackers created after enabling it will be listed.
+func (f *Faster) SetLongRunning(enabled bool) {
+	var ev = internal.Event{Type: internal.EvSetLongRunning}
+	if enabled {
+		ev.Value = 1
+	}
+	f.evChannel <- ev
+}
+
+// setLongRunning -- internal counterpart of SetLongRunning() (only called by the run() goroutine)
+func (f *Faster) setLongRunning(enabled bool) {
+	f.longRunningEnabled = enabled
+	if !f.recordsActive() {
+		f.active = make(map[*Tracker]struct{})
+	}
+}
+
+// recordsActive -- returns true if f.active has to be maintained (for LongRunning() or to clean up after dropped events)
+func (f *Faster) recordsActive() bool {
+	return f.longRunningEnabled || f.fullBufferPolicy == DropWhenFull
+}
+
+// LongRunning -- returns the Trackers that have been active for longer than threshold (oldest first)
+//
+// Use this to find goroutines that don't exit properly (or forgotten Done() calls).
+// Requires SetLongRunning(true) (or WithLongRunning()) - returns nothing otherwise.
+func (f *Faster) LongRunning(threshold time.Duration) []ActiveTracker {
+	f.do(internal.EvLongRunning, nil, threshold)
+	var rc = <-f.longRunningChannel
+
+	for i := range rc {
+		rc[i].Stack = resolveStack(rc[i].stack)
+	}
+	return rc
+}
+
+// longRunning -- internal (-> thread-unsafe) counterpart of LongRunning()
+//
+// should only ever be called from within the run() goroutine
+func (f *Faster) longRunning(now time.Time, threshold time.Duration) []ActiveTracker {
+	if !f.longRunningEnabled {
+		return nil // f.active might still be maintained for DropWhenFull
+	}
+
+	var rc []ActiveTracker
+	for t := range f.active {
+		var age = now.Sub(t.startTS)
+		if age > threshold {
+			rc = append(rc, ActiveTracker{
+				Path:    t.path,
+				StartTS: t.startTS,
+				Age:     age,
+				stack:   t.stack,
+			})
+		}
+	}
+
+	sort.Slice(rc, func(i, j int) bool {
+		return rc[i].StartTS.Before(rc[j].StartTS)
+	})
+	return rc
+}
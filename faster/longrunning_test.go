@@ -0,0 +1,80 @@
+package faster
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLongRunning(t *testing.T) {
+	f := New(WithLongRunning())
+
+	leaked := f.Track("leaked")
+	f.SetCaptureStacks(true)
+	leakedWithStack := f.Track("leakedWithStack")
+	child := leakedWithStack.NewChild("child")
+	f.Track("done").Done()
+
+	time.Sleep(20 * time.Millisecond)
+	f.Track("young")
+
+	assert.Empty(t, f.LongRunning(time.Hour))
+
+	list := f.LongRunning(10 * time.Millisecond)
+	if assert.Len(t, list, 3) {
+		assert.Equal(t, []string{"leaked"}, list[0].Path)
+		assert.True(t, list[0].Age >= 20*time.Millisecond)
+		assert.Empty(t, list[0].Stack)
+		assert.Equal(t, "", list[0].Caller())
+
+		for _, entry := range list[1:] {
+			assert.Contains(t, [][]string{{"leakedWithStack"}, {"leakedWithStack", "child"}}, entry.Path)
+			if assert.NotEmpty(t, entry.Stack) {
+				assert.True(t, strings.HasSuffix(entry.Stack[0].Function, ".TestLongRunning"), entry.Stack[0].Function)
+			}
+			assert.Contains(t, entry.Caller(), "longrunning_test.go:")
+			assert.Contains(t, entry.StackString(), "TestLongRunning()\n")
+		}
+	}
+
+	assert.Len(t, f.LongRunning(0), 4)
+
+	leaked.Done()
+	child.Done()
+	leakedWithStack.Done()
+	list = f.LongRunning(0)
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, []string{"young"}, list[0].Path)
+	}
+
+	f.Reset()
+	assert.Empty(t, f.LongRunning(0))
+}
+
+func TestLongRunningDisabled(t *testing.T) {
+	var f = New()
+	var before = f.Track("foo")
+	f.SetLongRunning(true)
+	var after = f.Track("foo")
+	assert.Len(t, f.LongRunning(0), 1, "only Trackers created after enabling it are listed")
+
+	f.SetLongRunning(false)
+	assert.Empty(t, f.LongRunning(0))
+	before.Done()
+	assert.Equal(t, int32(1), f.TakeSnapshot().Get("foo").Active())
+	after.Done()
+	assert.Equal(t, int32(0), f.TakeSnapshot().Get("foo").Active())
+
+	// Trackers created before Reset() don't affect the new active counts
+	var old = f.Track("bar")
+	f.Reset()
+	var cur = f.Track("bar")
+	old.Done()
+	var snap = f.TakeSnapshot()
+	assert.Equal(t, int32(1), snap.Get("bar").Active())
+	assert.Equal(t, int64(1), snap.Get("bar").Count())
+	cur.Done()
+	assert.Equal(t, int32(0), f.TakeSnapshot().Get("bar").Active())
+}
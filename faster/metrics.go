@@ -0,0 +1,94 @@
+package faster
+
+import (
+	"github.com/mreithub/go-faster/faster/internal"
+)
+
+// Counter -- adds up arbitrary values (e.g. bytes processed or cache hits) for a key (see Faster.Counter())
+//
+// Counters live in the same key tree as Trackers (and show up in Snapshots,
+// History time series and on the dashboard). Safe for concurrent use.
+type Counter struct {
+	parent *Faster
+	path   []string
+}
+
+// Counter -- returns the Counter for the given key
+//
+//	f.Counter("cache", "hits").Inc()
+//	f.Counter("upload", "bytes").Add(n)
+//
+// It's cheap to create (i.e. you don't need to keep a reference to it), but
+// each Add() call costs about as much as a Track().Done() pair.
+func (f *Faster) Counter(key ...string) *Counter {
+	return &Counter{parent: f, path: key}
+}
+
+// Add -- adds n to the Counter
+//
+// n shouldn't be negative (DataPoint.Sub() treats decreasing Counters like ones that were reset)
+func (c *Counter) Add(n int64) {
+	c.parent.send(internal.Event{
+		Type:  internal.EvCounterAdd,
+		Path:  c.path,
+		Delta: n,
+	})
+}
+
+// Inc -- adds 1 to the Counter
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Gauge -- stores the current value of something (e.g. a queue's depth) for a key (see Faster.Gauge())
+//
+// Gauges live in the same key tree as Trackers (and show up in Snapshots,
+// History time series and on the dashboard). Safe for concurrent use.
+type Gauge struct {
+	parent *Faster
+	path   []string
+}
+
+// Gauge -- returns the Gauge for the given key
+//
+//	f.Gauge("queue", "depth").Set(float64(len(queue)))
+func (f *Faster) Gauge(key ...string) *Gauge {
+	return &Gauge{parent: f, path: key}
+}
+
+// Set -- sets the Gauge's value
+func (g *Gauge) Set(v float64) {
+	g.parent.send(internal.Event{
+		Type:  internal.EvGaugeSet,
+		Path:  g.path,
+		Gauge: v,
+	})
+}
+
+// Add -- adds delta to the Gauge's value (e.g. +1 when enqueueing, -1 when dequeueing)
+func (g *Gauge) Add(delta float64) {
+	g.parent.send(internal.Event{
+		Type:  internal.EvGaugeAdd,
+		Path:  g.path,
+		Gauge: delta,
+	})
+}
+
+// onMetric -- updates the counter or gauge of the given path (EvCounterAdd, EvGaugeSet and EvGaugeAdd)
+func (f *Faster) onMetric(ev *internal.Event) {
+	var index = f.tree.GetIndex(ev.Path...)
+	f.touch(index, ev)
+
+	var d = f.getData(index)
+	switch ev.Type {
+	case internal.EvCounterAdd:
+		d.kind |= kindCounter
+		d.counter += ev.Delta
+	case internal.EvGaugeSet:
+		d.kind |= kindGauge
+		d.gauge = ev.Gauge
+	case internal.EvGaugeAdd:
+		d.kind |= kindGauge
+		d.gauge += ev.Gauge
+	}
+}
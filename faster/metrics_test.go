@@ -0,0 +1,93 @@
+package faster
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterAndGauge(t *testing.T) {
+	var f = New()
+	var hits = f.Counter("cache", "hits")
+	hits.Inc()
+	hits.Add(2)
+	f.Gauge("queue", "depth").Set(5)
+	f.Gauge("queue", "depth").Add(-1.5)
+	f.Track("cache").Done()
+
+	var snap = f.TakeSnapshot()
+	assert.Equal(t, int64(3), snap.Get("cache", "hits").Counter())
+	assert.Equal(t, int64(0), snap.Get("cache", "hits").Count())
+	assert.Equal(t, int64(1), snap.Get("cache").Count())
+	var gauge, ok = snap.Get("queue", "depth").Gauge()
+	assert.True(t, ok)
+	assert.Equal(t, 3.5, gauge)
+	_, ok = snap.Get("cache", "hits").Gauge()
+	assert.False(t, ok)
+	assert.True(t, snap.Get("cache", "hits").HasCounter())
+	assert.False(t, snap.Get("cache").HasCounter())
+
+	// Counters that (still) add up to 0 are Counters nonetheless
+	f.Counter("cache", "misses").Add(0)
+	assert.True(t, f.TakeSnapshot().Get("cache", "misses").HasCounter())
+
+	// Sub() returns the Counter's increase (and the Gauge's current value)
+	hits.Add(4)
+	f.Gauge("queue", "depth").Set(1)
+	var diff = f.TakeSnapshot().Sub(snap)
+	assert.Equal(t, int64(4), diff.Get("cache", "hits").Counter())
+	gauge, _ = diff.Get("queue", "depth").Gauge()
+	assert.Equal(t, 1.0, gauge)
+	assert.Equal(t, int64(4), f.TakeSnapshot().Get("cache", "hits").Sub(snap.Get("cache", "hits")).Counter())
+
+	// Merge() adds them up
+	var merged = Snapshots{snap, snap}.Merge()
+	assert.Equal(t, int64(6), merged.Get("cache", "hits").Counter())
+	gauge, _ = merged.Get("queue", "depth").Gauge()
+	assert.Equal(t, 7.0, gauge)
+
+	// JSON only contains the values the keys actually have
+	var buf, _ = json.Marshal(snap)
+	var parsed SnapshotJSON
+	assert.NoError(t, json.Unmarshal(buf, &parsed))
+	if assert.Len(t, parsed.Keys, 3) {
+		assert.Nil(t, parsed.Keys[0].Counter) // cache
+		assert.Equal(t, int64(3), *parsed.Keys[1].Counter)
+		assert.Nil(t, parsed.Keys[1].Gauge)
+		assert.Equal(t, 3.5, *parsed.Keys[2].Gauge)
+	}
+}
+
+func TestCounterHistory(t *testing.T) {
+	var f = New()
+	var h = NewManualHistory("test", time.Second, 10)
+	var bytes = f.Counter("upload", "bytes")
+
+	bytes.Add(100)
+	h.Push(f.TakeSnapshot())
+	bytes.Add(50)
+	h.Push(f.TakeSnapshot())
+	bytes.Add(25)
+	h.Push(f.TakeSnapshot())
+
+	var series = h.GetData("upload", "bytes").Relative()
+	if assert.Len(t, series.Data, 2) {
+		assert.Equal(t, int64(50), series.Data[0].Counter())
+		assert.Equal(t, int64(25), series.Data[1].Counter())
+	}
+}
+
+func TestDroppedMetrics(t *testing.T) {
+	var f = New(WithEventBuffer(1), WithFullBufferPolicy(DropWhenFull))
+	var release = blockRun(f)
+
+	f.Counter("a").Inc() // fills up the buffer
+	f.Counter("a").Inc()
+	f.Gauge("b").Set(1)
+	release()
+
+	assert.Equal(t, DroppedEvents{Counter: 1, Gauge: 1}, f.DroppedEvents())
+	assert.Equal(t, int64(1), f.TakeSnapshot().Get("a").Counter())
+}
@@ -0,0 +1,126 @@
+package faster
+
+import (
+	"time"
+
+	"github.com/mreithub/go-faster/faster/internal"
+)
+
+// Option -- configures a Faster instance (see New())
+type Option func(*options)
+
+// HistogramType -- the kind of Histogram recorded for each key (see WithHistogramType())
+type HistogramType int
+
+const (
+	// NoHistograms -- only record counts and total durations (saves ~270 bytes per key)
+	NoHistograms HistogramType = iota
+	// Log2Histograms -- record durations in power-of-two buckets (the default)
+	Log2Histograms
+)
+
+// FullBufferPolicy -- what Track() and Done() do if the event buffer is full (see WithFullBufferPolicy())
+type FullBufferPolicy int
+
+const (
+	// BlockWhenFull -- wait until the run() goroutine caught up (the default, never loses data)
+	BlockWhenFull FullBufferPolicy = iota
+	// DropWhenFull -- drop the event (and count it, see Faster.DroppedEvents())
+	DropWhenFull
+)
+
+// options -- settings collected by the Option functions
+type options struct {
+	histogramType    HistogramType
+	keyLimit         int
+	eventBuffer      int
+	clock            Clock
+	fullBufferPolicy FullBufferPolicy
+	tickers          []tickerOption
+	sampleMode       SampleMode
+	sampleRate       int
+	runtimeMetrics   bool
+	longRunning      bool
+}
+
+// tickerOption -- a History ticker to set up on creation (see WithTicker())
+type tickerOption struct {
+	name     string
+	interval time.Duration
+	keep     int
+}
+
+var defaultOptions = options{
+	histogramType:    Log2Histograms,
+	keyLimit:         1000,
+	eventBuffer:      100,
+	clock:            SystemClock,
+	fullBufferPolicy: BlockWhenFull,
+	sampleRate:       1,
+}
+
+// WithHistograms -- enables (the default) or disables histograms (shorthand for WithHistogramType())
+func WithHistograms(enabled bool) Option {
+	if enabled {
+		return WithHistogramType(Log2Histograms)
+	}
+	return WithHistogramType(NoHistograms)
+}
+
+// WithHistogramType -- sets the kind of Histogram recorded for each key
+func WithHistogramType(t HistogramType) Option {
+	return func(o *options) { o.histogramType = t }
+}
+
+// WithKeyLimit -- limits the number of keys (i.e. tree nodes), defaults to 1000
+//
+// Everything exceeding that limit will end up in the root path "_overflow".
+// Set to <0 to disable (note that this might cause memory issues if used with unchecked input)
+func WithKeyLimit(limit int) Option {
+	return func(o *options) { o.keyLimit = limit }
+}
+
+// WithEventBuffer -- sets the size of the buffer between Track()/Done() and the run() goroutine (defaults to 100)
+func WithEventBuffer(size int) Option {
+	return func(o *options) {
+		if size < 0 {
+			size = 0
+		}
+		o.eventBuffer = size
+	}
+}
+
+// WithClock -- sets the source of all timestamps (defaults to SystemClock)
+//
+// e.g. the fake clock in the fastertest package, or a cheaper (coarser) time source
+// if time.Now() turns out to be too slow on your platform.
+func WithClock(clock Clock) Option {
+	return func(o *options) { o.clock = clock }
+}
+
+// WithTicker -- sets up periodic snapshots right away (see Faster.SetTicker(), can be used more than once)
+func WithTicker(name string, interval time.Duration, keep int) Option {
+	return func(o *options) {
+		o.tickers = append(o.tickers, tickerOption{name: name, interval: interval, keep: keep})
+	}
+}
+
+// WithFullBufferPolicy -- sets what happens to tracking events if the event buffer is full
+//
+// Only affects Track() and Done() (and other tracking calls) - requests like
+// TakeSnapshot() always wait for the run() goroutine.
+func WithFullBufferPolicy(p FullBufferPolicy) Option {
+	return func(o *options) { o.fullBufferPolicy = p }
+}
+
+// SetLimit -- set a limit for Faster data points (i.e. tree nodes)
+//
+// Everything exceeding that limit will end up in the root path "_overflow".
+// Set to <0 to disable (note that this might cause memory issues if used with unchecked input)
+// Defaults to 1000 (see WithKeyLimit())
+func (f *Faster) SetLimit(newLimit int) {
+	f.evChannel <- internal.Event{
+		Type:  internal.EvSetLimit,
+		Value: newLimit,
+	}
+}
@@ -0,0 +1,70 @@
+package faster
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mreithub/go-faster/faster/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+// blockRun -- keeps the run() goroutine busy until release() is called (by filling up longRunningChannel)
+func blockRun(f *Faster) (release func()) {
+	var n = cap(f.longRunningChannel) + 1
+	for i := 1; i < n; i++ {
+		f.longRunningChannel <- nil
+	}
+	f.evChannel <- internal.Event{Type: internal.EvLongRunning}
+	for len(f.evChannel) > 0 {
+		runtime.Gosched()
+	}
+
+	return func() {
+		for i := 0; i < n; i++ {
+			<-f.longRunningChannel
+		}
+	}
+}
+
+func TestOptions(t *testing.T) {
+	var f = New(WithHistograms(false), WithKeyLimit(3), WithTicker("sec", time.Second, 5))
+	defer f.SetTicker("sec", 0, 0)
+
+	f.Track("a").Done()
+	f.Track("b").Done()
+	f.Track("c").Done()
+
+	var snap = f.TakeSnapshot()
+	assert.ElementsMatch(t, []string{"_overflow", "a", "b"}, snap.Children())
+	assert.NotNil(t, snap.Get("a"))
+	assert.Nil(t, snap.GetHistogram("a"))
+	assert.Contains(t, f.ListTickers(), "sec")
+
+	f.SetLimit(-1)
+	f.Track("d").Done()
+	assert.NotNil(t, f.TakeSnapshot().Get("d"))
+}
+
+func TestDropWhenFull(t *testing.T) {
+	var f = New(WithEventBuffer(2), WithFullBufferPolicy(DropWhenFull))
+	var release = blockRun(f)
+
+	f.Track("foo").Done()
+	f.Track("foo").Done()
+	assert.Equal(t, DroppedEvents{Track: 1, Done: 1}, f.DroppedEvents())
+
+	release()
+	var data = f.TakeSnapshot().Get("foo")
+	if assert.NotNil(t, data) {
+		assert.Equal(t, int64(1), data.Count())
+	}
+
+	// the default policy doesn't drop anything
+	f = New(WithEventBuffer(0))
+	for i := 0; i < 100; i++ {
+		f.Track("foo").Done()
+	}
+	assert.Equal(t, int64(100), f.TakeSnapshot().Get("foo").Count())
+	assert.Equal(t, DroppedEvents{}, f.DroppedEvents())
+}
@@ -0,0 +1,47 @@
+package faster
+
+import "github.com/mreithub/go-faster/faster/internal"
+
+// WithRuntimeMetrics -- records Go runtime metrics below the "_runtime" key (see SetRuntimeMetrics())
+func WithRuntimeMetrics() Option {
+	return func(o *options) { o.runtimeMetrics = true }
+}
+
+// SetRuntimeMetrics -- enables (or disables) recording Go runtime metrics below the reserved "_runtime" key
+//
+// If enabled, each Snapshot (including the ones taken by History tickers)
+// reads the current values from runtime/metrics:
+//
+//	_runtime/heap/objects, _runtime/heap/goal, _runtime/memory/total  bytes (Gauge)
+//	_runtime/heap/allocs                                               bytes allocated so far (Counter)
+//	_runtime/gc/cycles                                                 completed GC cycles (Counter)
+//	_runtime/gc/pauses                                                 stop-the-world GC pauses (like a tracked key - count, total time and histogram)
+//	_runtime/goroutines                                                live goroutines (Gauge)
+//	_runtime/sched/latency                                             time goroutines spent runnable before running (like a tracked key)
+//	_runtime/cgo/calls                                                 calls from Go to C (Counter)
+//
+// Requires Go 1.16 (does nothing on older versions). Disabling it keeps the
+// keys (with their last values) until Reset() is called.
+func (f *Faster) SetRuntimeMetrics(enabled bool) {
+	var ev = internal.Event{Type: internal.EvSetRuntimeMetrics}
+	if enabled {
+		ev.Value = 1
+	}
+	f.evChannel <- ev
+}
+
+// setRuntimeMetrics -- sets up (or removes) the runtime metrics collector (only called by the run() goroutine)
+func (f *Faster) setRuntimeMetrics(enabled bool) {
+	if !enabled {
+		f.runtime = nil
+	} else if f.runtime == nil {
+		f.runtime = newRuntimeCollector()
+	}
+}
+
+// collectRuntime -- records the current runtime metrics (if enabled, only called by the run() goroutine)
+func (f *Faster) collectRuntime() {
+	if f.runtime != nil {
+		f.runtime.collect(f)
+	}
+}
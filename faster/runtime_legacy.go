@@ -0,0 +1,11 @@
+//go:build !go1.16
+// +build !go1.16
+
+package faster
+
+// runtimeCollector -- runtime/metrics isn't available before Go 1.16 (-> records nothing)
+type runtimeCollector struct{}
+
+func newRuntimeCollector() *runtimeCollector { return nil }
+
+func (*runtimeCollector) collect(*Faster) {}
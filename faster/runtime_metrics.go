@@ -0,0 +1,119 @@
+//go:build go1.16
+// +build go1.16
+
+package faster
+
+import (
+	"math"
+	"runtime/metrics"
+	"time"
+)
+
+// runtimeMetric -- maps a runtime/metrics value to a key below "_runtime"
+type runtimeMetric struct {
+	// names -- runtime/metrics names (the first one supported by the running Go version will be used)
+	names []string
+	path  []string
+	// kind -- kindCounter or kindGauge for single values (histograms are recorded like tracked keys)
+	kind uint8
+}
+
+// runtimeMetrics -- the values recorded by SetRuntimeMetrics()
+var runtimeMetrics = []runtimeMetric{
+	{names: []string{"/memory/classes/heap/objects:bytes"}, path: []string{"heap", "objects"}, kind: kindGauge},
+	{names: []string{"/gc/heap/goal:bytes"}, path: []string{"heap", "goal"}, kind: kindGauge},
+	{names: []string{"/memory/classes/total:bytes"}, path: []string{"memory", "total"}, kind: kindGauge},
+	{names: []string{"/gc/heap/allocs:bytes"}, path: []string{"heap", "allocs"}, kind: kindCounter},
+	{names: []string{"/gc/cycles/total:gc-cycles"}, path: []string{"gc", "cycles"}, kind: kindCounter},
+	{names: []string{"/sched/pauses/total/gc:seconds", "/gc/pauses:seconds"}, path: []string{"gc", "pauses"}},
+	{names: []string{"/sched/goroutines:goroutines"}, path: []string{"goroutines"}, kind: kindGauge},
+	{names: []string{"/sched/latencies:seconds"}, path: []string{"sched", "latency"}},
+	{names: []string{"/cgo/go-to-c-calls:calls"}, path: []string{"cgo", "calls"}, kind: kindCounter},
+}
+
+// runtimeCollector -- reads runtime/metrics (see Faster.SetRuntimeMetrics())
+type runtimeCollector struct {
+	samples []metrics.Sample
+	// metrics -- the runtimeMetric for each sample
+	metrics []*runtimeMetric
+}
+
+func newRuntimeCollector() *runtimeCollector {
+	var supported = make(map[string]bool)
+	for _, desc := range metrics.All() {
+		supported[desc.Name] = true
+	}
+
+	var rc runtimeCollector
+	for i := range runtimeMetrics {
+		var m = &runtimeMetrics[i]
+		for _, name := range m.names {
+			if supported[name] {
+				rc.samples = append(rc.samples, metrics.Sample{Name: name})
+				rc.metrics = append(rc.metrics, m)
+				break
+			}
+		}
+	}
+	return &rc
+}
+
+// collect -- reads the current values, storing them below "_runtime" (only called by the run() goroutine)
+func (c *runtimeCollector) collect(f *Faster) {
+	metrics.Read(c.samples)
+
+	var path = make([]string, 0, 4)
+	for i, sample := range c.samples {
+		var m = c.metrics[i]
+		path = append(append(path[:0], "_runtime"), m.path...)
+		var index = f.tree.GetIndex(path...)
+		f.touch(index, nil) // runtime histograms (unlike Counters and Gauges) wouldn't be exempt from eviction
+		var d = f.getData(index)
+
+		switch sample.Value.Kind() {
+		case metrics.KindUint64:
+			c.setValue(d, m.kind, float64(sample.Value.Uint64()))
+		case metrics.KindFloat64:
+			c.setValue(d, m.kind, sample.Value.Float64())
+		case metrics.KindFloat64Histogram:
+			var h = convertHistogram(sample.Value.Float64Histogram())
+			d.count, d.totalTime = h.count, h.sum
+			if f.withHistograms {
+				*f.store.getHistogram(index) = h
+			}
+		}
+	}
+}
+
+// setValue -- stores a single runtime metric value as Counter or Gauge
+func (*runtimeCollector) setValue(d *data, kind uint8, v float64) {
+	d.kind |= kind
+	if kind == kindCounter {
+		d.counter = int64(v)
+	} else {
+		d.gauge = v
+	}
+}
+
+// convertHistogram -- converts a (cumulative) runtime/metrics histogram (in seconds) to a Histogram
+//
+// Each bucket's values are assumed to be at its midpoint (or its finite bound
+// for the outermost ones), so Sum() and Average() are estimates.
+func convertHistogram(src *metrics.Float64Histogram) Histogram {
+	var rc Histogram
+	for i, count := range src.Counts {
+		if count == 0 {
+			continue
+		}
+
+		var low, high = src.Buckets[i], src.Buckets[i+1]
+		var seconds = (low + high) / 2
+		if math.IsInf(low, -1) {
+			seconds = high
+		} else if math.IsInf(high, 1) {
+			seconds = low
+		}
+		rc.addN(time.Duration(seconds*float64(time.Second)), int64(count))
+	}
+	return rc
+}
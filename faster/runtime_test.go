@@ -0,0 +1,41 @@
+//go:build go1.16
+// +build go1.16
+
+package faster_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mreithub/go-faster/faster"
+	"github.com/mreithub/go-faster/faster/fastertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntimeMetrics(t *testing.T) {
+	var f, clock = fastertest.NewWithClock(t, faster.WithRuntimeMetrics())
+	f.SetTicker("sec", time.Second, 10)
+	runtime.GC()
+
+	var snap = f.TakeSnapshot()
+	var goroutines, ok = snap.Get("_runtime", "goroutines").Gauge()
+	assert.True(t, ok)
+	assert.True(t, goroutines > 0)
+	var heap, _ = snap.Get("_runtime", "heap", "objects").Gauge()
+	assert.True(t, heap > 0)
+	assert.True(t, snap.Get("_runtime", "gc", "cycles").Counter() >= 1)
+	assert.True(t, snap.Get("_runtime", "gc", "pauses").Count() >= 1)
+	assert.NotNil(t, snap.GetHistogram("_runtime", "gc", "pauses"))
+
+	// history tickers record them too
+	clock.Advance(2 * time.Second)
+	assert.Len(t, f.ListTickers()["sec"].GetData("_runtime", "goroutines").Data, 3)
+
+	// disabling keeps the last values
+	f.SetRuntimeMetrics(false)
+	runtime.GC()
+	var cycles = f.TakeSnapshot().Get("_runtime", "gc", "cycles").Counter()
+	runtime.GC()
+	assert.Equal(t, cycles, f.TakeSnapshot().Get("_runtime", "gc", "cycles").Counter())
+}
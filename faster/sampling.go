@@ -0,0 +1,217 @@
+package faster
+
+import (
+	"math/rand"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/mreithub/go-faster/faster/internal"
+)
+
+// SampleMode -- how sampled calls are chosen (see WithSampling() and Faster.NewSampler())
+type SampleMode int
+
+const (
+	// SampleEvery -- tracks every n-th call (starting with the first one)
+	SampleEvery SampleMode = iota
+	// SampleRandom -- tracks each call with a probability of 1/n
+	SampleRandom
+)
+
+// sample -- returns true if the given call (1-based) should be tracked
+func (m SampleMode) sample(call, n int64) bool {
+	if n <= 1 {
+		return true
+	} else if m == SampleRandom {
+		return rand.Int63n(n) == 0
+	}
+	return (call-1)%n == 0
+}
+
+// WithSampling -- only tracks one in n calls of the Faster instance (scaling up counts and total durations accordingly)
+//
+// Histograms (and slow calls) only contain the sampled calls, Active() values
+// only count sampled Trackers. Untracked calls get a no-op Tracker (see
+// Tracker.Sampled() - it keeps its path, so children and spans still nest
+// correctly). The rate is available as Snapshot.SampleRate(). Use NewSampler() to
+// only sample individual (hot) keys instead.
+func WithSampling(mode SampleMode, n int) Option {
+	return func(o *options) {
+		if n < 1 {
+			n = 1
+		}
+		o.sampleMode, o.sampleRate = mode, n
+	}
+}
+
+// trackSampled -- checks whether the current call should be tracked (with per-instance sampling enabled)
+//
+// returns the number of calls the Tracker stands for (or 0 if it shouldn't be tracked)
+func (f *Faster) trackSampled() int64 {
+	var call = atomic.AddInt64(&f.sampleCalls, 1)
+	if !f.sampleMode.sample(call, int64(f.sampleRate)) {
+		return 0
+	}
+	return int64(f.sampleRate)
+}
+
+// Sampler -- tracks one in n calls of a single key (see Faster.NewSampler())
+//
+// Unlike per-instance sampling (see WithSampling()), a Sampler counts all calls
+// (using an atomic counter), so Count() values are exact - only TotalTime()
+// values are estimated. Calls that weren't accounted for by a sampled Tracker
+// yet are added each time a Snapshot is taken (see flushSamplers()). Safe for
+// concurrent use.
+type Sampler struct {
+	// number of Track() calls (accessed atomically, 64-bit aligned)
+	calls int64
+	// number of calls accounted for by sampled Trackers (accessed atomically)
+	reported int64
+
+	parent *Faster
+	path   []string
+	mode   SampleMode
+	n      int64
+}
+
+// NewSampler -- returns a Sampler tracking one in n calls of the given key
+//
+// Use it (instead of Faster.Track()) for very hot code paths:
+//
+//	var sampler = faster.Singleton.NewSampler(faster.SampleEvery, 100, "parser", "next")
+//
+//	func next() {
+//		defer sampler.Track().Done()
+//		// ...
+//	}
+//
+// The rate applies to the key and its children (see Snapshot.SampleRate()).
+func (f *Faster) NewSampler(mode SampleMode, n int, key ...string) *Sampler {
+	if n < 1 {
+		n = 1
+	}
+	var rc = &Sampler{
+		parent: f,
+		path:   key,
+		mode:   mode,
+		n:      int64(n),
+	}
+	f.evChannel <- internal.Event{
+		Type:  internal.EvSetSampleRate,
+		Path:  key,
+		Value: n,
+		Data:  rc,
+	}
+	return rc
+}
+
+// Track -- tracks the current call if it was sampled (returns a no-op Tracker otherwise, see Tracker.Sampled())
+func (s *Sampler) Track() *Tracker {
+	var call = atomic.AddInt64(&s.calls, 1)
+	if !s.mode.sample(call, s.n) {
+		return notSampled(s.parent, s.path)
+	}
+
+	// the Tracker stands for all the calls since the last sampled one
+	var weight int64
+	for {
+		var prev = atomic.LoadInt64(&s.reported)
+		if call <= prev {
+			break // a concurrent call has already accounted for this one
+		}
+		if atomic.CompareAndSwapInt64(&s.reported, prev, call) {
+			weight = call - prev
+			break
+		}
+	}
+
+	return s.parent.track(s.path, weight)
+}
+
+// notSampled -- returns the no-op Tracker for calls that weren't sampled
+func notSampled(f *Faster, path []string) *Tracker {
+	return &Tracker{
+		parent:     f,
+		path:       path,
+		sampledOut: true,
+	}
+}
+
+// setSampleRate -- records the sample rate of the given key (only called by the run() goroutine)
+func (f *Faster) setSampleRate(path []string, n int, sampler *Sampler) {
+	if f.sampleRates == nil {
+		f.sampleRates = make(map[string]int)
+	}
+	f.sampleRates[pathKey(path)] = n
+	if sampler != nil {
+		f.samplers = append(f.samplers, sampler)
+	}
+}
+
+// flushSamplers -- adds the calls of each Sampler that haven't been accounted for by a sampled Tracker yet (only called by the run() goroutine)
+//
+// Their total time is estimated using the key's current average.
+func (f *Faster) flushSamplers() {
+	for _, s := range f.samplers {
+		for {
+			var calls = atomic.LoadInt64(&s.calls)
+			var prev = atomic.LoadInt64(&s.reported)
+			if calls <= prev {
+				break
+			}
+			if atomic.CompareAndSwapInt64(&s.reported, prev, calls) {
+				var d = f.getDataForPath(s.path...)
+				var n = calls - prev
+				d.totalTime += d.Average() * time.Duration(n)
+				d.count += n
+				break
+			}
+		}
+	}
+}
+
+// SampleRate -- returns n if the given key was sampled with a rate of one in n (1 if all calls were tracked)
+//
+// The rate of a Sampler applies to its key and all its children, the rate of
+// the Faster instance (see WithSampling()) applies to all other keys. Merged
+// Snapshots report the coarsest rate of their sources.
+func (s *Snapshot) SampleRate(path ...string) int {
+	for i := len(path); i >= 0 && len(s.sampleRates) > 0; i-- {
+		if n, ok := s.sampleRates[pathKey(path[:i])]; ok {
+			return n
+		}
+	}
+	if s.sampleRate > 1 {
+		return s.sampleRate
+	}
+	return 1
+}
+
+// sampledKeys -- returns the paths of all keys with their own sample rate (sorted, used by the binary encoding)
+func (s *Snapshot) sampledKeys() [][]string {
+	var keys = make([]string, 0, len(s.sampleRates))
+	for key := range s.sampleRates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var rc = make([][]string, len(keys))
+	for i, key := range keys {
+		rc[i] = splitPathKey(key)
+	}
+	return rc
+}
+
+// mergeSampleRates -- adds the rates of src to dst (keeping the coarser one if both have a rate for the same key)
+func mergeSampleRates(dst, src map[string]int) map[string]int {
+	for key, n := range src {
+		if dst == nil {
+			dst = make(map[string]int, len(src))
+		}
+		if n > dst[key] {
+			dst[key] = n
+		}
+	}
+	return dst
+}
@@ -0,0 +1,129 @@
+package faster_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mreithub/go-faster/faster"
+	"github.com/mreithub/go-faster/faster/fastertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampling(t *testing.T) {
+	var f, clock = fastertest.NewWithClock(t, faster.WithSampling(faster.SampleEvery, 4))
+
+	var tracked = 0
+	for i := 0; i < 8; i++ {
+		var ref = f.Track("a")
+		if ref.Sampled() {
+			tracked++
+		}
+		clock.Advance(time.Millisecond)
+		ref.Done()
+	}
+	assert.Equal(t, 2, tracked)
+
+	var snap = f.TakeSnapshot()
+	assert.Equal(t, int64(8), snap.Get("a").Count())
+	assert.Equal(t, 8*time.Millisecond, snap.Get("a").TotalTime())
+	assert.Equal(t, int64(2), snap.GetHistogram("a").Count())
+	assert.Equal(t, 4, snap.SampleRate("a"))
+	assert.Equal(t, 4, snap.JSON().SampleRate)
+	assert.Equal(t, 4, snap.JSON().Keys[0].SampleRate)
+}
+
+func TestSampler(t *testing.T) {
+	var f, clock = fastertest.NewWithClock(t)
+	var sampler = f.NewSampler(faster.SampleEvery, 10, "hot")
+
+	for i := 0; i < 95; i++ {
+		var ref = sampler.Track()
+		clock.Advance(time.Millisecond)
+		ref.NewChild("child").Done()
+		ref.Done()
+	}
+	f.Track("cold").Done()
+
+	// calls 1, 11, ..., 91 were sampled (the last 4 calls are added when taking the Snapshot)
+	var snap = f.TakeSnapshot()
+	assert.Equal(t, int64(95), snap.Get("hot").Count())
+	assert.Equal(t, 95*time.Millisecond, snap.Get("hot").TotalTime())
+	assert.Equal(t, int64(10), snap.GetHistogram("hot").Count())
+	assert.Equal(t, int64(91), snap.Get("hot", "child").Count()) // children are only scaled by the sampled Trackers
+
+	// the next sampled call doesn't count them again
+	for i := 0; i < 6; i++ {
+		sampler.Track().Done()
+	}
+	assert.Equal(t, int64(101), f.TakeSnapshot().Get("hot").Count())
+
+	assert.Equal(t, 10, snap.SampleRate("hot"))
+	assert.Equal(t, 10, snap.SampleRate("hot", "child"))
+	assert.Equal(t, 1, snap.SampleRate("cold"))
+	assert.Equal(t, 1, snap.SampleRate())
+
+	// sample rates are part of the binary encoding (and merged Snapshots keep the coarsest one)
+	var buf, err = snap.MarshalBinary()
+	assert.NoError(t, err)
+	var decoded faster.Snapshot
+	if assert.NoError(t, decoded.UnmarshalBinary(buf)) {
+		assert.Equal(t, 10, decoded.SampleRate("hot", "child"))
+	}
+	var other = fastertest.New(t)
+	other.NewSampler(faster.SampleRandom, 100, "hot")
+	var merged = faster.Snapshots{snap, other.TakeSnapshot()}.Merge()
+	assert.Equal(t, 100, merged.SampleRate("hot"))
+	assert.Equal(t, 10, snap.Sub(snap).SampleRate("hot"))
+}
+
+func TestSampleRandom(t *testing.T) {
+	var f = fastertest.New(t)
+	var sampler = f.NewSampler(faster.SampleRandom, 2, "random")
+
+	var tracked = 0
+	for i := 0; i < 1000; i++ {
+		var ref = sampler.Track()
+		if ref.Sampled() {
+			tracked++
+		}
+		ref.Done()
+	}
+
+	// counts are exact
+	assert.Equal(t, int64(1000), f.TakeSnapshot().Get("random").Count())
+	assert.True(t, tracked > 400 && tracked < 600, "unexpected number of sampled calls: %d", tracked)
+}
+
+func TestNilTracker(t *testing.T) {
+	var ref *faster.Tracker
+	assert.Nil(t, ref.SetAttr("foo", "bar").WatchContext(context.Background()).NewChild("child"))
+	assert.Nil(t, ref.Path())
+	assert.True(t, ref.StartTS().IsZero())
+	assert.Equal(t, time.Duration(0), ref.Took())
+	ref.Done()
+}
+
+func TestNotSampled(t *testing.T) {
+	var f = fastertest.New(t, faster.WithSampling(faster.SampleEvery, 2))
+	var ctx, _ = f.StartSpan(context.Background(), "sampled")
+
+	var ref = f.Track("http", "GET /")
+	assert.False(t, ref.Sampled())
+	assert.Equal(t, []string{"http", "GET /"}, ref.Path())
+
+	// children (and spans) of unsampled calls keep their path
+	var child = ref.NewChild("db")
+	assert.Equal(t, []string{"http", "GET /", "db"}, child.Path())
+	var _, span = f.StartSpan(faster.WithTracker(ctx, ref), "render")
+	assert.Equal(t, []string{"http", "GET /", "render"}, span.Path())
+	assert.True(t, span.Sampled())
+	span.Done()
+	child.Done()
+	ref.Done()
+
+	var snap = f.TakeSnapshot()
+	assert.Nil(t, snap.Get("http", "GET /", "db"))
+	assert.Equal(t, int64(2), snap.Get("http", "GET /", "render").Count())
+	assert.Nil(t, snap.Get("render"))
+}
@@ -3,7 +3,7 @@ package faster
 import "time"
 
 // Singleton -- global go-faster instance
-var Singleton = New(true)
+var Singleton = New()
 
 // TakeSnapshot -- Returns a Snapshot of the current Faster state
 func TakeSnapshot() *Snapshot {
@@ -30,3 +30,8 @@ func Reset() {
 func SetTicker(name string, interval time.Duration, keep int) {
 	Singleton.SetTicker(name, interval, keep)
 }
+
+// SetAlert -- adds (or replaces) an AlertRule (in singleton mode)
+func SetAlert(rule AlertRule, onChange func(Alert)) {
+	Singleton.SetAlert(rule, onChange)
+}
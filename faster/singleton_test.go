@@ -45,7 +45,7 @@ func TestSingleton(t *testing.T) {
 	assert.Equal(t, int32(1), d2.Active())
 	assert.Equal(t, int64(0), d2.Count())
 	assert.Equal(t, time.Duration(0), d2.TotalTime())
-	assert.Equal(t, 3, len(snap1.data))
+	assert.Equal(t, 1, len(snap1.store.data)) // (a single chunk)
 	assert.Equal(t, 2, len(snap1.tree.Children()))
 
 	// snap2: snap1 + Done('world')
@@ -56,7 +56,7 @@ func TestSingleton(t *testing.T) {
 	assert.Equal(t, int32(0), d2.Active())
 	assert.Equal(t, int64(1), d2.Count())
 	assert.True(t, d2.TotalTime() > 0)
-	assert.Equal(t, 3, len(snap1.data))
+	assert.Equal(t, 1, len(snap1.store.data)) // (a single chunk)
 	assert.Equal(t, 2, len(snap2.tree.Children()))
 
 	// test reflection
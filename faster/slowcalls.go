@@ -0,0 +1,160 @@
+package faster
+
+import (
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/mreithub/go-faster/faster/internal"
+)
+
+// maxSlowCalls -- number of Exemplars kept for each key (older ones will be discarded)
+const maxSlowCalls = 10
+
+// Exemplar -- a single slow call (see Faster.SetSlowCallThreshold())
+type Exemplar struct {
+	// TS -- time the call finished (i.e. Done() was called)
+	TS time.Time `json:"ts"`
+	// Took -- the call's duration
+	Took time.Duration `json:"took"`
+	// Attrs -- attributes set using Tracker.SetAttr() (e.g. request or trace IDs)
+	Attrs map[string]string `json:"attrs,omitempty"`
+	// Stack -- the Tracker's creation stack (only set if enabled - see Faster.SetCaptureStacks())
+	Stack []runtime.Frame `json:"-"`
+
+	// unresolved Stack
+	stack []uintptr
+}
+
+// Caller -- returns the creation site of the call's Tracker ("file:line" - or "" if unknown)
+func (e *Exemplar) Caller() string {
+	var t = ActiveTracker{Stack: e.Stack}
+	return t.Caller()
+}
+
+// exemplarRing -- ring buffer of the most recent Exemplars of a key
+type exemplarRing struct {
+	entries []Exemplar
+	next    int
+}
+
+func (r *exemplarRing) push(e Exemplar) {
+	if len(r.entries) < maxSlowCalls {
+		r.entries = append(r.entries, e)
+	} else {
+		r.entries[r.next] = e
+	}
+	r.next = (r.next + 1) % maxSlowCalls
+}
+
+// list -- returns a copy of the ring's entries (slowest first)
+func (r *exemplarRing) list() []Exemplar {
+	var rc = make([]Exemplar, len(r.entries))
+	copy(rc, r.entries)
+	sort.Slice(rc, func(i, j int) bool {
+		return rc[i].Took > rc[j].Took
+	})
+	return rc
+}
+
+// thresholdNode -- tree of slow call thresholds (the most specific one applies)
+type thresholdNode struct {
+	threshold time.Duration
+	children  map[string]*thresholdNode
+}
+
+// get -- returns the threshold for the given path (or 0 if not set)
+func (n *thresholdNode) get(path []string) time.Duration {
+	var rc = n.threshold
+	for _, name := range path {
+		if n = n.children[name]; n == nil {
+			break
+		}
+		if n.threshold > 0 {
+			rc = n.threshold
+		}
+	}
+	return rc
+}
+
+func (n *thresholdNode) set(path []string, threshold time.Duration) {
+	for _, name := range path {
+		var child = n.children[name]
+		if child == nil {
+			if n.children == nil {
+				n.children = make(map[string]*thresholdNode)
+			}
+			child = &thresholdNode{}
+			n.children[name] = child
+		}
+		n = child
+	}
+	n.threshold = threshold
+}
+
+// SetAttr -- sets an attribute of this Tracker (will be stored with slow call Exemplars - see Faster.SetSlowCallThreshold())
+//
+// Make sure to call this before Done(). Returns the Tracker itself (allowing for `faster.Track("foo").SetAttr("requestID", id)`)
+func (t *Tracker) SetAttr(key, value string) *Tracker {
+	if t == nil {
+		return nil
+	}
+	if t.attrs == nil {
+		t.attrs = make(map[string]string)
+	}
+	t.attrs[key] = value
+	return t
+}
+
+// SetSlowCallThreshold -- calls taking longer than threshold will be recorded as Exemplar
+//
+// The threshold applies to the given key and its children (unless they have their own
+// threshold) - or to all keys if omitted. Set to 0 to remove a key's threshold.
+// For each key, the last few Exemplars are kept (see Snapshot.GetSlowCalls())
+func (f *Faster) SetSlowCallThreshold(threshold time.Duration, key ...string) {
+	f.do(internal.EvSetSlowCallThreshold, key, threshold)
+}
+
+// onSlowCall -- checks t's duration against the configured thresholds (storing an Exemplar if necessary)
+func (f *Faster) onSlowCall(index int, t *Tracker, took time.Duration) {
+	var threshold = f.slowCallThresholds.get(t.path)
+	if threshold <= 0 || took < threshold {
+		return
+	}
+
+	var ring = f.slowCalls[index]
+	if ring == nil {
+		ring = &exemplarRing{}
+		f.slowCalls[index] = ring
+	}
+
+	var attrs map[string]string
+	if len(t.attrs) > 0 {
+		attrs = make(map[string]string, len(t.attrs))
+		for k, v := range t.attrs {
+			attrs[k] = v
+		}
+	}
+	ring.push(Exemplar{
+		TS:    t.startTS.Add(took),
+		Took:  took,
+		Attrs: attrs,
+		stack: t.stack,
+	})
+}
+
+// GetSlowCalls -- returns the slow call Exemplars recorded for the given key (slowest first)
+func (s *Snapshot) GetSlowCalls(path ...string) []Exemplar {
+	if s.tree == nil {
+		return nil
+	}
+
+	// copy the Exemplars (Snapshots are immutable)
+	var entries = s.slowCalls[s.tree.GetIndex(path...)]
+	var rc = make([]Exemplar, len(entries))
+	for i, e := range entries {
+		e.Stack = resolveStack(e.stack)
+		rc[i] = e
+	}
+	return rc
+}
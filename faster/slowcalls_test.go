@@ -0,0 +1,78 @@
+package faster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThresholds(t *testing.T) {
+	var n thresholdNode
+
+	assert.Equal(t, time.Duration(0), n.get(nil))
+	assert.Equal(t, time.Duration(0), n.get([]string{"http", "GET /"}))
+
+	n.set(nil, time.Second)
+	n.set([]string{"http"}, time.Millisecond)
+	n.set([]string{"http", "GET /slow"}, time.Minute)
+
+	assert.Equal(t, time.Second, n.get(nil))
+	assert.Equal(t, time.Second, n.get([]string{"app"}))
+	assert.Equal(t, time.Millisecond, n.get([]string{"http"}))
+	assert.Equal(t, time.Millisecond, n.get([]string{"http", "GET /"}))
+	assert.Equal(t, time.Minute, n.get([]string{"http", "GET /slow"}))
+	assert.Equal(t, time.Minute, n.get([]string{"http", "GET /slow", "child"}))
+
+	n.set([]string{"http", "GET /slow"}, 0)
+	assert.Equal(t, time.Millisecond, n.get([]string{"http", "GET /slow", "child"}))
+}
+
+func TestSlowCalls(t *testing.T) {
+	f := New()
+	f.SetSlowCallThreshold(10*time.Millisecond, "slow")
+
+	f.Track("slow").Done()
+	f.Track("other").Done()
+	assert.Empty(t, f.TakeSnapshot().GetSlowCalls("slow"))
+
+	f.SetCaptureStacks(true)
+	ref := f.Track("slow").SetAttr("requestID", "1234")
+	time.Sleep(20 * time.Millisecond)
+	ref.Done()
+
+	snap := f.TakeSnapshot()
+	calls := snap.GetSlowCalls("slow")
+	if assert.Len(t, calls, 1) {
+		assert.True(t, calls[0].Took >= 20*time.Millisecond)
+		assert.Equal(t, ref.Took(), calls[0].Took)
+		assert.Equal(t, ref.StartTS().Add(ref.Took()), calls[0].TS)
+		assert.Equal(t, map[string]string{"requestID": "1234"}, calls[0].Attrs)
+		assert.Contains(t, calls[0].Caller(), "slowcalls_test.go:")
+	}
+	assert.Empty(t, snap.GetSlowCalls("other"))
+	assert.Empty(t, snap.GetSlowCalls("unknown"))
+
+	// the ring should only keep the last few entries
+	f.SetSlowCallThreshold(time.Nanosecond)
+	for i := 0; i < 2*maxSlowCalls; i++ {
+		f.Track("other").Done()
+	}
+	assert.Len(t, f.TakeSnapshot().GetSlowCalls("other"), maxSlowCalls)
+	assert.Len(t, snap.GetSlowCalls("slow"), 1, "Snapshots should be immutable")
+
+	f.Reset()
+	assert.Empty(t, f.TakeSnapshot().GetSlowCalls("other"))
+}
+
+func TestExemplarRing(t *testing.T) {
+	var r exemplarRing
+	for i := 1; i <= maxSlowCalls+3; i++ {
+		r.push(Exemplar{Took: time.Duration(i)})
+	}
+
+	list := r.list()
+	assert.Len(t, list, maxSlowCalls)
+	assert.Equal(t, time.Duration(maxSlowCalls+3), list[0].Took)
+	assert.Equal(t, time.Duration(4), list[len(list)-1].Took)
+}
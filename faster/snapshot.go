@@ -1,6 +1,7 @@
 package faster
 
 import (
+	"sort"
 	"time"
 
 	"github.com/mreithub/go-faster/faster/internal"
@@ -8,9 +9,15 @@ import (
 
 // Snapshot -- point-in-time copy of go-faster's state
 type Snapshot struct {
-	tree       *internal.Tree
-	data       []data
-	histograms []Histogram
+	tree      *internal.Tree
+	store     store
+	slowCalls map[int][]Exemplar
+	dropped   []internal.Dropped
+	// tracking events dropped up to TS (see DroppedEvents())
+	droppedEvents DroppedEvents
+	// per-instance and per-key (by pathKey()) sample rates (see SampleRate())
+	sampleRate  int
+	sampleRates map[string]int
 
 	// Creation timestamp
 	TS time.Time `json:"ts"`
@@ -31,23 +38,249 @@ func (s *Snapshot) Get(path ...string) DataPoint {
 		return nil
 	}
 
-	var index = s.tree.GetIndex(path...)
-	if index >= 0 && index < len(s.data) {
-		rc = &s.data[index]
+	if d := s.store.readData(s.tree.GetIndex(path...)); d != nil {
+		rc = d
 	}
 	return rc
 }
 
 // GetHistogram -- returns the histogram for the given key (or nil if not found/disabled)
 func (s *Snapshot) GetHistogram(path ...string) *Histogram {
-	var rc *Histogram
-	if s.tree == nil || len(s.histograms) == 0 {
+	if s.tree == nil {
 		return nil
 	}
+	return s.store.readHistogram(s.tree.GetIndex(path...))
+}
 
-	var index = s.tree.GetIndex(path...)
-	if index >= 0 && index < len(s.histograms) {
-		rc = &s.histograms[index]
+// getData -- nil-safe version of Get() (returning the internal data type)
+func (s *Snapshot) getData(path []string) *data {
+	if s == nil || s.tree == nil {
+		return nil
+	}
+	return s.store.readData(s.tree.GetIndex(path...))
+}
+
+// getHistogram -- nil-safe version of GetHistogram()
+func (s *Snapshot) getHistogram(path []string) *Histogram {
+	if s == nil {
+		return nil
 	}
+	return s.GetHistogram(path...)
+}
+
+// walk -- calls fn for each node of this Snapshot's tree (including the root node)
+func (s *Snapshot) walk(fn func(path []string)) {
+	if s == nil || s.tree == nil {
+		return
+	}
+
+	var walkRec func(path []string)
+	walkRec = func(path []string) {
+		fn(path)
+		for _, name := range s.tree.Children(path...) {
+			var childPath = make([]string, len(path)+1)
+			copy(childPath, path)
+			childPath[len(path)] = name
+			walkRec(childPath)
+		}
+	}
+	walkRec(nil)
+}
+
+// snapshotBuilder -- creates new Snapshots (used by Snapshot.Sub() and Snapshots.Merge())
+type snapshotBuilder struct {
+	tree      internal.RWTree
+	store     store
+	slowCalls map[int][]Exemplar
+}
+
+// index -- returns the index for the given path (creating the node if necessary)
+func (b *snapshotBuilder) index(path []string) int {
+	return b.tree.GetIndex(path...)
+}
+
+func (b *snapshotBuilder) build(ts time.Time, dropped []internal.Dropped) *Snapshot {
+	return &Snapshot{
+		tree:      b.tree.Clone(),
+		store:     b.store.share(),
+		slowCalls: b.slowCalls,
+		dropped:   dropped,
+		TS:        ts,
+	}
+}
+
+// Sub -- returns the difference between this Snapshot and an older one
+//
+// Keys are matched by path (so the Snapshots may come from different Faster
+// instances or have different sets of keys). The returned Snapshot contains all
+// of this Snapshot's keys (with their current Active() and Gauge() values), counts, durations,
+// allocations and Counter values will be relative to older (see DataPoint.Sub() and Histogram.Since()). Only slow
+// calls recorded after older was taken are kept.
+func (s *Snapshot) Sub(older *Snapshot) *Snapshot {
+	var b = snapshotBuilder{
+		slowCalls: make(map[int][]Exemplar),
+	}
+
+	s.walk(func(path []string) {
+		var index = b.index(path)
+		if d := s.getData(path); d != nil {
+			var diff = *d
+			if old := older.getData(path); old != nil && d.count >= old.count {
+				diff.count -= old.count
+				diff.totalTime -= old.totalTime
+				diff.allocCalls -= old.allocCalls
+				diff.allocs -= old.allocs
+				diff.allocBytes -= old.allocBytes
+			}
+			if old := older.getData(path); old != nil && d.counter >= old.counter {
+				diff.counter -= old.counter
+			}
+			*b.store.getData(index) = diff
+		}
+
+		if h := s.GetHistogram(path...); h != nil {
+			if old := older.getHistogram(path); old != nil {
+				h = h.Since(*old)
+			}
+			*b.store.getHistogram(index) = *h
+		}
+
+		var calls []Exemplar
+		for _, e := range s.slowCalls[s.tree.GetIndex(path...)] {
+			if older == nil || e.TS.After(older.TS) {
+				calls = append(calls, e)
+			}
+		}
+		if len(calls) > 0 {
+			b.slowCalls[index] = calls
+		}
+	})
+
+	var rc = b.build(s.TS, s.dropped)
+	rc.sampleRate, rc.sampleRates = s.sampleRate, s.sampleRates
+	rc.droppedEvents = s.droppedEvents
+	if older != nil {
+		rc.droppedEvents = s.droppedEvents.sub(older.droppedEvents)
+	}
+	return rc
+}
+
+// Totals -- returns a copy of this Snapshot without its current Active() and Gauge() values
+//
+// Only the cumulative values (counts, durations, Counter(), allocations and histograms) are kept,
+// which is what remains of a process that's gone (e.g. to be merged with the Snapshots of
+// the live ones, see Snapshots.Merge()).
+func (s *Snapshot) Totals() *Snapshot {
+	var b = snapshotBuilder{
+		slowCalls: make(map[int][]Exemplar),
+	}
+
+	s.walk(func(path []string) {
+		var index = b.index(path)
+		if d := s.getData(path); d != nil {
+			var totals = *d
+			totals.active = 0
+			totals.gauge = 0
+			totals.kind &^= kindGauge
+			*b.store.getData(index) = totals
+		}
+		if h := s.GetHistogram(path...); h != nil {
+			*b.store.getHistogram(index) = *h
+		}
+		if calls := s.slowCalls[s.tree.GetIndex(path...)]; len(calls) > 0 {
+			b.slowCalls[index] = calls
+		}
+	})
+
+	var rc = b.build(s.TS, s.dropped)
+	rc.sampleRate, rc.sampleRates = s.sampleRate, s.sampleRates
+	rc.droppedEvents = s.droppedEvents
+	return rc
+}
+
+// Merge -- combines the given Snapshots (e.g. from several instances or processes) into one
+//
+// Keys are matched by path. Active(), Count(), TotalTime(), Counter(), Gauge()
+// and allocation values (and histograms) are added up, the slowest calls of all Snapshots are kept. The
+// merged Snapshot's TS will be the newest of the given ones. Returns nil if
+// the list is empty.
+func (l Snapshots) Merge() *Snapshot {
+	if len(l) == 0 {
+		return nil
+	}
+
+	var b = snapshotBuilder{
+		slowCalls: make(map[int][]Exemplar),
+	}
+	var ts time.Time
+	var droppedEvents DroppedEvents
+	var sampleRate int
+	var sampleRates map[string]int
+	var dropped = make(map[string]*internal.Dropped)
+	var droppedOrder []string
+
+	for _, s := range l {
+		if s == nil {
+			continue
+		}
+		if s.TS.After(ts) {
+			ts = s.TS
+		}
+		droppedEvents = droppedEvents.add(s.droppedEvents)
+		if s.sampleRate > sampleRate {
+			sampleRate = s.sampleRate
+		}
+		sampleRates = mergeSampleRates(sampleRates, s.sampleRates)
+
+		s.walk(func(path []string) {
+			var index = b.index(path)
+			if d := s.getData(path); d != nil {
+				var merged = b.store.getData(index)
+				merged.active += d.active
+				merged.count += d.count
+				merged.totalTime += d.totalTime
+				merged.counter += d.counter
+				merged.gauge += d.gauge
+				merged.allocCalls += d.allocCalls
+				merged.allocs += d.allocs
+				merged.allocBytes += d.allocBytes
+				merged.kind |= d.kind
+			}
+			if h := s.GetHistogram(path...); h != nil {
+				b.store.getHistogram(index).merge(h)
+			}
+			if calls := s.slowCalls[s.tree.GetIndex(path...)]; len(calls) > 0 {
+				b.slowCalls[index] = append(b.slowCalls[index], calls...)
+			}
+		})
+
+		for _, d := range s.dropped {
+			var key = pathKey(d.Path)
+			if dropped[key] == nil {
+				dropped[key] = &internal.Dropped{Path: d.Path}
+				droppedOrder = append(droppedOrder, key)
+			}
+			dropped[key].Distinct += d.Distinct
+		}
+	}
+
+	for index, calls := range b.slowCalls {
+		sort.Slice(calls, func(i, j int) bool {
+			return calls[i].Took > calls[j].Took
+		})
+		if len(calls) > maxSlowCalls {
+			b.slowCalls[index] = calls[:maxSlowCalls]
+		}
+	}
+
+	var droppedList = make([]internal.Dropped, 0, len(dropped))
+	sort.Strings(droppedOrder)
+	for _, key := range droppedOrder {
+		droppedList = append(droppedList, *dropped[key])
+	}
+
+	var rc = b.build(ts, droppedList)
+	rc.sampleRate, rc.sampleRates = sampleRate, sampleRates
+	rc.droppedEvents = droppedEvents
 	return rc
 }
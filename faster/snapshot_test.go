@@ -0,0 +1,141 @@
+package faster
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mreithub/go-faster/faster/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+// slowCall -- tracks a call taking at least 2ms
+func slowCall(f *Faster, key ...string) {
+	var ref = f.Track(key...)
+	time.Sleep(2 * time.Millisecond)
+	ref.Done()
+}
+
+// trackN -- tracks the given key n times (each call taking 'took')
+func trackN(f *Faster, n int, took time.Duration, key ...string) {
+	for i := 0; i < n; i++ {
+		f.do(internal.EvTrack, key, 0)
+		f.do(internal.EvDone, key, took)
+	}
+}
+
+func TestSnapshotSub(t *testing.T) {
+	f := New()
+	f.SetSlowCallThreshold(time.Millisecond)
+	trackN(f, 2, time.Second, "http", "GET /")
+	slowCall(f, "slow")
+	var older = f.TakeSnapshot()
+	slowCall(f, "slow")
+
+	trackN(f, 3, 2*time.Second, "http", "GET /")
+	trackN(f, 1, time.Second, "http", "POST /")
+	var active = f.Track("http", "POST /")
+	defer active.Done()
+	var newer = f.TakeSnapshot()
+
+	var diff = newer.Sub(older)
+	assert.Equal(t, newer.TS, diff.TS)
+	assert.Equal(t, int64(3), diff.Get("http", "GET /").Count())
+	assert.Equal(t, 6*time.Second, diff.Get("http", "GET /").TotalTime())
+	assert.Equal(t, int64(3), diff.GetHistogram("http", "GET /").Count())
+	assert.Equal(t, int64(1), diff.Get("http", "POST /").Count())
+	assert.Equal(t, int32(1), diff.Get("http", "POST /").Active())
+	assert.ElementsMatch(t, []string{"GET /", "POST /"}, diff.Children("http"))
+	assert.Len(t, diff.GetSlowCalls("slow"), 1)
+	assert.Len(t, newer.GetSlowCalls("slow"), 2)
+
+	// the original Snapshots aren't affected
+	assert.Equal(t, int64(5), newer.Get("http", "GET /").Count())
+	assert.Equal(t, int64(2), older.Get("http", "GET /").Count())
+
+	// keys that were reset in between
+	f.Reset()
+	trackN(f, 1, time.Second, "http", "GET /")
+	diff = f.TakeSnapshot().Sub(newer)
+	assert.Equal(t, int64(1), diff.Get("http", "GET /").Count())
+	assert.Nil(t, diff.Get("http", "POST /"))
+
+	// nil -> copy
+	diff = newer.Sub(nil)
+	assert.Equal(t, int64(5), diff.Get("http", "GET /").Count())
+}
+
+func TestSnapshotTotals(t *testing.T) {
+	f := New()
+	trackN(f, 2, time.Second, "http", "GET /")
+	f.Counter("requests").Add(3)
+	f.Gauge("queue").Set(1.5)
+	var active = f.Track("http", "GET /")
+	defer active.Done()
+	var snap = f.TakeSnapshot()
+
+	var totals = snap.Totals()
+	assert.Equal(t, snap.TS, totals.TS)
+	assert.Equal(t, int64(2), totals.Get("http", "GET /").Count())
+	assert.Equal(t, 2*time.Second, totals.Get("http", "GET /").TotalTime())
+	assert.Equal(t, int32(0), totals.Get("http", "GET /").Active())
+	assert.Equal(t, int64(2), totals.GetHistogram("http", "GET /").Count())
+	assert.Equal(t, int64(3), totals.Get("requests").Counter())
+	var _, hasGauge = totals.Get("queue").Gauge()
+	assert.False(t, hasGauge)
+
+	// merging the totals of a process that's gone doesn't affect Active() or Gauge()
+	var merged = Snapshots{totals, snap}.Merge()
+	assert.Equal(t, int64(4), merged.Get("http", "GET /").Count())
+	assert.Equal(t, int32(1), merged.Get("http", "GET /").Active())
+	var gauge, _ = merged.Get("queue").Gauge()
+	assert.Equal(t, 1.5, gauge)
+}
+
+func TestSnapshotsMerge(t *testing.T) {
+	assert.Nil(t, Snapshots{}.Merge())
+
+	a, b := New(), New(WithHistograms(false))
+	a.SetSlowCallThreshold(time.Millisecond)
+	b.SetSlowCallThreshold(time.Millisecond)
+	b.SetFanOutLimit(1, "http")
+	for i := 0; i < 6; i++ {
+		slowCall(a, "slow")
+		slowCall(b, "slow")
+	}
+	trackN(a, 2, time.Second, "http", "GET /")
+	trackN(a, 1, time.Second, "db", "query")
+	trackN(b, 3, 3*time.Second, "http", "GET /")
+	trackN(b, 1, time.Second, "http", "POST /")
+
+	var snapA, snapB = a.TakeSnapshot(), b.TakeSnapshot()
+	var merged = Snapshots{snapA, nil, snapB}.Merge()
+
+	assert.Equal(t, snapB.TS, merged.TS)
+	assert.Equal(t, int64(5), merged.Get("http", "GET /").Count())
+	assert.Equal(t, 11*time.Second, merged.Get("http", "GET /").TotalTime())
+	assert.Equal(t, int64(2), merged.GetHistogram("http", "GET /").Count())
+	assert.Equal(t, int64(1), merged.Get("db", "query").Count())
+	assert.Equal(t, int64(1), merged.Get("http", "_other").Count())
+	assert.Equal(t, []DroppedKeys{{Path: []string{"http", "_other"}, Distinct: 1}}, merged.DroppedKeys())
+	assert.ElementsMatch(t, []string{"http", "db", "slow"}, merged.Children())
+	assert.Equal(t, int64(12), merged.Get("slow").Count())
+	assert.Len(t, merged.GetSlowCalls("slow"), maxSlowCalls)
+}
+
+func TestSnapshotJSON(t *testing.T) {
+	f := New()
+	trackN(f, 2, time.Second, "http", "GET /")
+	f.Track("http", "POST /")
+	var data, err = json.Marshal(f.TakeSnapshot())
+	assert.NoError(t, err)
+
+	var parsed SnapshotJSON
+	assert.NoError(t, json.Unmarshal(data, &parsed))
+	assert.False(t, parsed.TS.IsZero())
+	assert.Equal(t, []KeyJSON{
+		{Path: []string{"http", "GET /"}, Count: 2, TotalTime: 2 * time.Second, Average: time.Second, P50: parsed.Keys[0].P50, P90: parsed.Keys[0].P90, P99: parsed.Keys[0].P99},
+		{Path: []string{"http", "POST /"}, Active: 1},
+	}, parsed.Keys)
+	assert.True(t, parsed.Keys[0].P99 > 500*time.Millisecond)
+}
@@ -0,0 +1,162 @@
+package sqltrack
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxFingerprintLen -- fingerprints will be truncated to this length (in bytes)
+const maxFingerprintLen = 200
+
+// Fingerprint -- returns a normalized version of the given SQL query
+//
+// String and numeric literals as well as placeholders ($1, :name, @p1, ...) are
+// replaced with '?', lists of those (e.g. `IN (1, 2, 3)`) are collapsed to '(?+)',
+// comments are removed and whitespace is collapsed.
+//
+// Queries that only differ in their parameters will therefore end up with the same
+// fingerprint (keeping the number of go-faster keys at bay)
+func Fingerprint(query string) string {
+	var rc strings.Builder
+	rc.Grow(len(query))
+
+	var lastSpace = true // (skips leading whitespace)
+	var writeSpace = func() {
+		if !lastSpace {
+			rc.WriteByte(' ')
+			lastSpace = true
+		}
+	}
+	var write = func(s string) {
+		rc.WriteString(s)
+		lastSpace = false
+	}
+
+	for i := 0; i < len(query); {
+		var c = query[i]
+		switch {
+		case c == '\'':
+			// string literal (escaped quotes ('') are handled as two adjacent literals)
+			var end = strings.IndexByte(query[i+1:], '\'')
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += end + 2
+			}
+			if !strings.HasSuffix(rc.String(), "?") {
+				write("?")
+			}
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			// line comment
+			if end := strings.IndexByte(query[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(query)
+			}
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			// block comment
+			if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(query)
+			}
+			writeSpace()
+		case c == ':' && strings.HasPrefix(query[i:], "::"):
+			// postgres type cast
+			write("::")
+			i += 2
+		case c == '$' || c == ':' || c == '@':
+			// placeholders ($1, :name, @p1)
+			if n := wordLen(query[i+1:]); n > 0 {
+				write("?")
+				i += n + 1
+			} else {
+				write(query[i : i+1])
+				i++
+			}
+		case isDigit(c) && (i == 0 || !isWordChar(query[i-1])):
+			// numeric literal
+			i++
+			for i < len(query) && (isDigit(query[i]) || query[i] == '.' || query[i] == 'e' || query[i] == 'E') {
+				i++
+			}
+			write("?")
+		case unicode.IsSpace(rune(c)):
+			writeSpace()
+			i++
+		default:
+			if isWordChar(c) {
+				var end = i + wordLen(query[i:])
+				write(query[i:end])
+				i = end
+			} else {
+				write(query[i : i+1])
+				i++
+			}
+		}
+	}
+
+	var fp = collapseLists(strings.TrimSpace(rc.String()))
+	if len(fp) > maxFingerprintLen {
+		// don't split multi-byte characters
+		var end = maxFingerprintLen
+		for end > 0 && !utf8.RuneStart(fp[end]) {
+			end--
+		}
+		fp = fp[:end]
+	}
+	return fp
+}
+
+// collapseLists -- replaces lists of placeholders like '(?, ?, ?)' with '(?+)'
+func collapseLists(query string) string {
+	var rc strings.Builder
+	for {
+		var start = strings.Index(query, "(?")
+		if start < 0 {
+			break
+		}
+
+		var end = start + 2
+		for end < len(query) {
+			var rest = strings.TrimLeft(query[end:], " ")
+			if strings.HasPrefix(rest, ",") {
+				rest = strings.TrimLeft(rest[1:], " ")
+				if strings.HasPrefix(rest, "?") {
+					end = len(query) - len(rest) + 1
+					continue
+				}
+			}
+			break
+		}
+
+		var rest = strings.TrimLeft(query[end:], " ")
+		rc.WriteString(query[:start])
+		if strings.HasPrefix(rest, ")") {
+			rc.WriteString("(?+)")
+			query = rest[1:]
+		} else {
+			rc.WriteString(query[start:end])
+			query = query[end:]
+		}
+	}
+	rc.WriteString(query)
+
+	return rc.String()
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isWordChar(c byte) bool {
+	return c == '_' || isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// wordLen -- returns the length of the identifier/keyword at the start of s
+func wordLen(s string) int {
+	var rc = 0
+	for rc < len(s) && isWordChar(s[rc]) {
+		rc++
+	}
+	return rc
+}
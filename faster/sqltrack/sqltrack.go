@@ -0,0 +1,367 @@
+// Package sqltrack -- database/sql driver wrapper tracking query execution times using go-faster
+//
+// Wrapped drivers track Exec, Query, Prepare, Begin, Commit and Rollback calls as
+// "db", <name>, <op> (with an additional normalized query leaf key for Exec, Query
+// and Prepare if WithFingerprints() is used - see Fingerprint()).
+//
+// Note that the number of distinct keys is bounded by the Faster instance's limit
+// (see Faster.SetLimit()) - keys exceeding that limit will end up in "_overflow".
+//
+// Usage:
+//
+//	var w = sqltrack.New(faster.Singleton, "psql")
+//	var db = sql.OpenDB(w.WrapConnector(connector))
+//	// or:
+//	sql.Register("psql-faster", w.Wrap(&pq.Driver{}))
+package sqltrack
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/mreithub/go-faster/faster"
+)
+
+var (
+	errNamedArgs = errors.New("sqltrack: named parameters aren't supported by the underlying driver")
+	// same errors as the ones database/sql returns for drivers that don't implement driver.ConnBeginTx
+	errIsolationLevel = errors.New("sql: driver does not support non-default isolation level")
+	errReadOnly       = errors.New("sql: driver does not support read-only transactions")
+)
+
+// Wrapper -- wraps database/sql drivers (tracking their calls)
+type Wrapper struct {
+	faster *faster.Faster
+	name   string
+	// fingerprints -- see WithFingerprints()
+	fingerprints bool
+}
+
+// Option -- configures a Wrapper (see New())
+type Option func(w *Wrapper)
+
+// WithFingerprints -- additionally tracks Exec, Query and Prepare calls with the normalized query as child key (see Fingerprint())
+func WithFingerprints() Option {
+	return func(w *Wrapper) {
+		w.fingerprints = true
+	}
+}
+
+// New -- returns a Wrapper tracking database calls as "db", name, <op> in f
+func New(f *faster.Faster, name string, opts ...Option) *Wrapper {
+	var rc = Wrapper{
+		faster: f,
+		name:   name,
+	}
+	for _, opt := range opts {
+		opt(&rc)
+	}
+	return &rc
+}
+
+// Wrap -- returns a driver.Driver tracking all calls of d (to be used with sql.Register())
+func (w *Wrapper) Wrap(d driver.Driver) driver.Driver {
+	return &wrappedDriver{
+		parent: d,
+		w:      w,
+	}
+}
+
+// WrapConnector -- returns a driver.Connector tracking all calls of c (to be used with sql.OpenDB())
+func (w *Wrapper) WrapConnector(c driver.Connector) driver.Connector {
+	return &wrappedConnector{
+		parent: c,
+		w:      w,
+	}
+}
+
+// track -- tracks the given operation (and the query fingerprint as its child - if enabled
+// and query isn't empty), returns a function to be called once the operation's done
+func (w *Wrapper) track(op string, query string) func() {
+	var ref = w.faster.Track("db", w.name, op)
+	if !w.fingerprints || query == "" {
+		return ref.Done
+	}
+
+	var child = ref.NewChild(Fingerprint(query))
+	return func() {
+		child.Done()
+		ref.Done()
+	}
+}
+
+//
+// driver.Driver, driver.DriverContext and driver.Connector
+//
+
+type wrappedDriver struct {
+	parent driver.Driver
+	w      *Wrapper
+}
+
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	var conn, err = d.parent.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedConn{parent: conn, w: d.w}, nil
+}
+
+func (d *wrappedDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.parent.(driver.DriverContext); ok {
+		var c, err = dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &wrappedConnector{parent: c, w: d.w, driver: d}, nil
+	}
+	return &dsnConnector{dsn: name, driver: d}, nil
+}
+
+// dsnConnector -- driver.Connector for drivers that don't implement driver.DriverContext
+type dsnConnector struct {
+	dsn    string
+	driver *wrappedDriver
+}
+
+func (c *dsnConnector) Connect(_ context.Context) (driver.Conn, error) { return c.driver.Open(c.dsn) }
+func (c *dsnConnector) Driver() driver.Driver                          { return c.driver }
+
+type wrappedConnector struct {
+	parent driver.Connector
+	w      *Wrapper
+	driver driver.Driver
+}
+
+func (c *wrappedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	var conn, err = c.parent.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedConn{parent: conn, w: c.w}, nil
+}
+
+func (c *wrappedConnector) Driver() driver.Driver {
+	if c.driver != nil {
+		return c.driver
+	}
+	return &wrappedDriver{parent: c.parent.Driver(), w: c.w}
+}
+
+//
+// driver.Conn (and its optional interfaces)
+//
+
+type wrappedConn struct {
+	parent driver.Conn
+	w      *Wrapper
+}
+
+func (c *wrappedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *wrappedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	defer c.w.track("Prepare", query)()
+
+	var stmt driver.Stmt
+	var err error
+	if pc, ok := c.parent.(driver.ConnPrepareContext); ok {
+		stmt, err = pc.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.parent.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedStmt{parent: stmt, w: c.w, query: query}, nil
+}
+
+func (c *wrappedConn) Close() error {
+	return c.parent.Close()
+}
+
+func (c *wrappedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *wrappedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var bc, hasBeginTx = c.parent.(driver.ConnBeginTx)
+	if !hasBeginTx {
+		// Begin() can't honor opts -> fail like database/sql would for the unwrapped driver
+		if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+			return nil, errIsolationLevel
+		} else if opts.ReadOnly {
+			return nil, errReadOnly
+		}
+	}
+	defer c.w.track("Begin", "")()
+
+	var tx driver.Tx
+	var err error
+	if hasBeginTx {
+		tx, err = bc.BeginTx(ctx, opts)
+	} else {
+		tx, err = c.parent.Begin()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedTx{parent: tx, w: c.w}, nil
+}
+
+func (c *wrappedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if ec, ok := c.parent.(driver.ExecerContext); ok {
+		defer c.w.track("Exec", query)()
+		return ec.ExecContext(ctx, query, args)
+	} else if e, ok := c.parent.(driver.Execer); ok {
+		var values, err = namedValuesToValues(args)
+		if err != nil {
+			return nil, err
+		}
+		defer c.w.track("Exec", query)()
+		return e.Exec(query, values)
+	}
+
+	// database/sql will fall back to Prepare() + Stmt.Exec()
+	return nil, driver.ErrSkip
+}
+
+func (c *wrappedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if qc, ok := c.parent.(driver.QueryerContext); ok {
+		defer c.w.track("Query", query)()
+		return qc.QueryContext(ctx, query, args)
+	} else if q, ok := c.parent.(driver.Queryer); ok {
+		var values, err = namedValuesToValues(args)
+		if err != nil {
+			return nil, err
+		}
+		defer c.w.track("Query", query)()
+		return q.Query(query, values)
+	}
+
+	// database/sql will fall back to Prepare() + Stmt.Query()
+	return nil, driver.ErrSkip
+}
+
+// Ping -- conns that don't implement driver.Pinger are assumed to be alive (like database/sql does)
+func (c *wrappedConn) Ping(ctx context.Context) error {
+	if p, ok := c.parent.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *wrappedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.parent.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *wrappedConn) IsValid() bool {
+	if v, ok := c.parent.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *wrappedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.parent.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+//
+// driver.Stmt
+//
+
+type wrappedStmt struct {
+	parent driver.Stmt
+	w      *Wrapper
+	query  string
+}
+
+func (s *wrappedStmt) Close() error  { return s.parent.Close() }
+func (s *wrappedStmt) NumInput() int { return s.parent.NumInput() }
+
+func (s *wrappedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	defer s.w.track("Exec", s.query)()
+	return s.parent.Exec(args)
+}
+
+func (s *wrappedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	defer s.w.track("Query", s.query)()
+	return s.parent.Query(args)
+}
+
+func (s *wrappedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if ec, ok := s.parent.(driver.StmtExecContext); ok {
+		defer s.w.track("Exec", s.query)()
+		return ec.ExecContext(ctx, args)
+	}
+
+	var values, err = namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Exec(values)
+}
+
+func (s *wrappedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if qc, ok := s.parent.(driver.StmtQueryContext); ok {
+		defer s.w.track("Query", s.query)()
+		return qc.QueryContext(ctx, args)
+	}
+
+	var values, err = namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Query(values)
+}
+
+func (s *wrappedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := s.parent.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	} else if cc, ok := s.parent.(driver.ColumnConverter); ok {
+		var err error
+		nv.Value, err = cc.ColumnConverter(nv.Ordinal - 1).ConvertValue(nv.Value)
+		return err
+	}
+	return driver.ErrSkip
+}
+
+//
+// driver.Tx
+//
+
+type wrappedTx struct {
+	parent driver.Tx
+	w      *Wrapper
+}
+
+func (t *wrappedTx) Commit() error {
+	defer t.w.track("Commit", "")()
+	return t.parent.Commit()
+}
+
+func (t *wrappedTx) Rollback() error {
+	defer t.w.track("Rollback", "")()
+	return t.parent.Rollback()
+}
+
+// namedValuesToValues -- converts args for drivers that don't support the context-aware interfaces (named args aren't supported there)
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	var rc = make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errNamedArgs
+		}
+		rc[i] = arg.Value
+	}
+	return rc, nil
+}
@@ -0,0 +1,228 @@
+package sqltrack
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mreithub/go-faster/faster"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDriver -- minimal in-memory driver.Driver (returning a single row with a single column for each query)
+type fakeDriver struct {
+	// if set, the returned connections will implement driver.ExecerContext and driver.QueryerContext
+	withExecer bool
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	if d.withExecer {
+		return &fakeExecerConn{}, nil
+	}
+	return &fakeConn{}, nil
+}
+
+type fakeConnector struct{}
+
+func (c *fakeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeExecerConn{}, nil
+}
+func (c *fakeConnector) Driver() driver.Driver { return &fakeDriver{withExecer: true} }
+
+type fakeConn struct{}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return &fakeTx{}, nil }
+
+type fakeExecerConn struct{ fakeConn }
+
+func (c *fakeExecerConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeExecerConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+type fakeStmt struct{}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) { return &fakeRows{}, nil }
+
+type fakeTx struct{}
+
+func (t *fakeTx) Commit() error   { return nil }
+func (t *fakeTx) Rollback() error { return nil }
+
+type fakeRows struct{ done bool }
+
+func (r *fakeRows) Columns() []string { return []string{"value"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	dest[0] = int64(42)
+	r.done = true
+	return nil
+}
+
+// exercise -- runs a couple of queries against db
+func exercise(t *testing.T, db *sql.DB) {
+	var _, err = db.Exec("UPDATE foo SET bar = $1 WHERE id = 1", "baz")
+	assert.NoError(t, err)
+
+	var value int64
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, db.QueryRow("SELECT value FROM foo WHERE id = ?", i).Scan(&value))
+		assert.Equal(t, int64(42), value)
+	}
+
+	var tx *sql.Tx
+	tx, err = db.Begin()
+	assert.NoError(t, err)
+	_, err = tx.Exec("DELETE FROM foo WHERE id IN (1, 2, 3)")
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Commit())
+}
+
+func TestWrap(t *testing.T) {
+	var f = faster.New(faster.WithHistograms(false))
+	var w = New(f, "fake")
+
+	// what sql.Open() does for registered drivers (sql.Register() would panic when running the test more than once)
+	var connector, err = w.Wrap(&fakeDriver{}).(driver.DriverContext).OpenConnector("")
+	assert.NoError(t, err)
+	var db = sql.OpenDB(connector)
+	defer db.Close()
+	exercise(t, db)
+
+	// fakeConn doesn't implement ExecerContext/QueryerContext -> database/sql prepares each statement
+	var snap = f.TakeSnapshot()
+	assert.Equal(t, int64(4), snap.Get("db", "fake", "Prepare").Count())
+	assert.Equal(t, int64(2), snap.Get("db", "fake", "Exec").Count())
+	assert.Equal(t, int64(2), snap.Get("db", "fake", "Query").Count())
+	assert.Equal(t, int64(1), snap.Get("db", "fake", "Begin").Count())
+	assert.Equal(t, int64(1), snap.Get("db", "fake", "Commit").Count())
+	assert.Nil(t, snap.Get("db", "fake", "Rollback"))
+	assert.Empty(t, snap.Children("db", "fake", "Query"))
+}
+
+func TestWrapConnector(t *testing.T) {
+	var f = faster.New(faster.WithHistograms(false))
+	var w = New(f, "fake", WithFingerprints())
+
+	var db = sql.OpenDB(w.WrapConnector(&fakeConnector{}))
+	defer db.Close()
+	exercise(t, db)
+
+	var snap = f.TakeSnapshot()
+	assert.Nil(t, snap.Get("db", "fake", "Prepare"))
+	assert.Equal(t, int64(2), snap.Get("db", "fake", "Exec").Count())
+	assert.Equal(t, int64(2), snap.Get("db", "fake", "Query").Count())
+	assert.Equal(t, int64(1), snap.Get("db", "fake", "Commit").Count())
+	assert.Empty(t, snap.Children("db", "fake", "Commit"))
+
+	assert.ElementsMatch(t, []string{"UPDATE foo SET bar = ? WHERE id = ?", "DELETE FROM foo WHERE id IN (?+)"}, snap.Children("db", "fake", "Exec"))
+	assert.Equal(t, []string{"SELECT value FROM foo WHERE id = ?"}, snap.Children("db", "fake", "Query"))
+	assert.Equal(t, int64(2), snap.Get("db", "fake", "Query", "SELECT value FROM foo WHERE id = ?").Count())
+}
+
+func TestFingerprint(t *testing.T) {
+	var tests = map[string]string{
+		"SELECT * FROM foo":                                          "SELECT * FROM foo",
+		"  SELECT *\n\tFROM   foo  ":                                 "SELECT * FROM foo",
+		"SELECT * FROM foo WHERE id = 123 AND name = 'bar'":          "SELECT * FROM foo WHERE id = ? AND name = ?",
+		"SELECT * FROM foo WHERE name = 'it''s'":                     "SELECT * FROM foo WHERE name = ?",
+		"SELECT * FROM t1 WHERE x = $1 AND y = :name AND z = @p3":    "SELECT * FROM t1 WHERE x = ? AND y = ? AND z = ?",
+		"SELECT '1'::int, 1.5e3":                                     "SELECT ?::int, ?",
+		"SELECT * FROM foo WHERE id IN (1, 2,3 , 4)":                 "SELECT * FROM foo WHERE id IN (?+)",
+		"INSERT INTO foo (a, b) VALUES (?, ?), ($1, $2)":             "INSERT INTO foo (a, b) VALUES (?+), (?+)",
+		"SELECT count(?) FROM foo":                                   "SELECT count(?+) FROM foo",
+		"SELECT * -- comment\nFROM foo /* block\ncomment */ WHERE 1": "SELECT * FROM foo WHERE ?",
+	}
+
+	for query, expected := range tests {
+		assert.Equal(t, expected, Fingerprint(query), query)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	var f = faster.New(faster.WithHistograms(false))
+	f.SetLimit(6) // root, db, fake, Exec, 2 fingerprints
+	var w = New(f, "fake", WithFingerprints())
+
+	var db = sql.OpenDB(w.WrapConnector(&fakeConnector{}))
+	defer db.Close()
+
+	for _, table := range []string{"a", "b", "c", "d"} {
+		var _, err = db.Exec("DELETE FROM " + table)
+		assert.NoError(t, err)
+	}
+
+	var snap = f.TakeSnapshot()
+	assert.Equal(t, int64(4), snap.Get("db", "fake", "Exec").Count())
+	assert.Equal(t, []string{"DELETE FROM a", "DELETE FROM b"}, sorted(snap.Children("db", "fake", "Exec")))
+	assert.Equal(t, int64(2), snap.Get("_overflow").Count())
+}
+
+func sorted(values []string) []string {
+	sort.Strings(values)
+	return values
+}
+
+func TestPing(t *testing.T) {
+	var conn = closeCountingConn{}
+	var wrapped = wrappedConn{parent: &conn, w: New(faster.New(faster.WithHistograms(false)), "fake")}
+
+	// fakeConn doesn't implement driver.Pinger -> the connection is assumed to be alive
+	assert.NoError(t, wrapped.Ping(context.Background()))
+	assert.Equal(t, 0, conn.closed)
+}
+
+// closeCountingConn -- fakeConn counting Close() calls
+type closeCountingConn struct {
+	fakeConn
+	closed int
+}
+
+func (c *closeCountingConn) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestBeginTxOptions(t *testing.T) {
+	var f = faster.New(faster.WithHistograms(false))
+	var db = sql.OpenDB(New(f, "fake").WrapConnector(&fakeConnector{}))
+	defer db.Close()
+
+	// fakeConn doesn't implement driver.ConnBeginTx -> non-default options can't be honored
+	var _, err = db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelSerializable})
+	assert.EqualError(t, err, "sql: driver does not support non-default isolation level")
+	_, err = db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	assert.EqualError(t, err, "sql: driver does not support read-only transactions")
+
+	var tx *sql.Tx
+	tx, err = db.BeginTx(context.Background(), &sql.TxOptions{})
+	if assert.NoError(t, err) {
+		assert.NoError(t, tx.Rollback())
+	}
+	assert.Equal(t, int64(1), f.TakeSnapshot().Get("db", "fake", "Begin").Count())
+}
+
+func TestFingerprintTruncation(t *testing.T) {
+	var query = "SELECT " + strings.Repeat("x", maxFingerprintLen-8) + "äöü"
+	var fp = Fingerprint(query)
+	assert.True(t, utf8.ValidString(fp), fp)
+	assert.Equal(t, "SELECT "+strings.Repeat("x", maxFingerprintLen-8), fp)
+}
@@ -0,0 +1,98 @@
+package faster
+
+// chunkSize -- number of data points (and histograms) per chunk
+const chunkSize = 64
+
+// dataChunk -- fixed size block of data points (shared between the store and Snapshots until modified)
+type dataChunk struct {
+	gen    uint64
+	values [chunkSize]data
+}
+
+// histogramChunk -- fixed size block of histograms (see dataChunk)
+type histogramChunk struct {
+	gen    uint64
+	values [chunkSize]Histogram
+}
+
+// store -- copy-on-write storage of data points and histograms (by tree index)
+//
+// Snapshots share the store's chunks. Each chunk remembers the generation it was
+// created (or copied) in - chunks of older generations are shared and will be
+// copied before they're modified. This way taking a Snapshot only costs O(chunks)
+// and subsequent changes O(changed chunks).
+type store struct {
+	gen        uint64
+	data       []*dataChunk
+	histograms []*histogramChunk
+}
+
+// share -- returns a read-only copy of the store (the chunks themselves aren't copied)
+func (s *store) share() store {
+	var rc = store{
+		gen:        s.gen,
+		data:       make([]*dataChunk, len(s.data)),
+		histograms: make([]*histogramChunk, len(s.histograms)),
+	}
+	copy(rc.data, s.data)
+	copy(rc.histograms, s.histograms)
+
+	// mark all existing chunks as shared
+	s.gen++
+	return rc
+}
+
+// len -- returns the number of allocated data slots
+func (s *store) len() int {
+	return len(s.data) * chunkSize
+}
+
+// getData -- returns a modifiable pointer to the data point with the given index (extending the store if necessary)
+func (s *store) getData(index int) *data {
+	var i = index / chunkSize
+	for i >= len(s.data) {
+		s.data = append(s.data, &dataChunk{gen: s.gen})
+	}
+
+	var chunk = s.data[i]
+	if chunk.gen != s.gen {
+		var copied = *chunk
+		copied.gen = s.gen
+		chunk = &copied
+		s.data[i] = chunk
+	}
+	return &chunk.values[index%chunkSize]
+}
+
+// getHistogram -- returns a modifiable pointer to the histogram with the given index (extending the store if necessary)
+func (s *store) getHistogram(index int) *Histogram {
+	var i = index / chunkSize
+	for i >= len(s.histograms) {
+		s.histograms = append(s.histograms, &histogramChunk{gen: s.gen})
+	}
+
+	var chunk = s.histograms[i]
+	if chunk.gen != s.gen {
+		var copied = *chunk
+		copied.gen = s.gen
+		chunk = &copied
+		s.histograms[i] = chunk
+	}
+	return &chunk.values[index%chunkSize]
+}
+
+// readData -- returns the data point with the given index (or nil if out of range - mustn't be modified)
+func (s *store) readData(index int) *data {
+	if index < 0 || index/chunkSize >= len(s.data) {
+		return nil
+	}
+	return &s.data[index/chunkSize].values[index%chunkSize]
+}
+
+// readHistogram -- returns the histogram with the given index (or nil if out of range - mustn't be modified)
+func (s *store) readHistogram(index int) *Histogram {
+	if index < 0 || index/chunkSize >= len(s.histograms) {
+		return nil
+	}
+	return &s.histograms[index/chunkSize].values[index%chunkSize]
+}
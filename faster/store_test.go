@@ -0,0 +1,41 @@
+package faster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStoreCopyOnWrite -- makes sure shared chunks are copied before being modified
+func TestStoreCopyOnWrite(t *testing.T) {
+	var s store
+	s.getData(1).Done(time.Second, 1)
+	s.getData(chunkSize+1).Done(time.Second, 1)
+	s.getHistogram(1).Add(time.Second)
+
+	var shared = s.share()
+	assert.Equal(t, 2*chunkSize, shared.len())
+	assert.Nil(t, shared.readData(2*chunkSize))
+	assert.Nil(t, shared.readHistogram(chunkSize))
+
+	s.getData(1).Done(time.Second, 1)
+	s.getHistogram(1).Add(time.Second)
+	s.getData(3*chunkSize).Done(time.Second, 1)
+
+	assert.Equal(t, int64(1), shared.readData(1).Count())
+	assert.Equal(t, int64(1), shared.readHistogram(1).Count())
+	assert.Nil(t, shared.readData(3*chunkSize))
+	assert.Equal(t, int64(2), s.readData(1).Count())
+	assert.Equal(t, int64(2), s.readHistogram(1).Count())
+	assert.Equal(t, int64(1), s.readData(3*chunkSize).Count())
+
+	// only modified chunks get copied
+	assert.True(t, shared.data[1] == s.data[1])
+	assert.False(t, shared.data[0] == s.data[0])
+
+	// ... and only once per generation
+	var chunk = s.data[0]
+	s.getData(2).Done(time.Second, 1)
+	assert.True(t, chunk == s.data[0])
+}
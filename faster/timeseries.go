@@ -10,44 +10,304 @@ type TimeSeries struct {
 	Path []string
 	// Data -- data over time (index 0 was taken at StartTS)
 	Data []DataPoint
-	// StartTS -- timestamp of the first Data point
+	// StartTS -- timestamp of the first Data point (for relative TimeSeries: the start of the first interval)
 	StartTS time.Time
-	// Interval -- interval between Data points (note that )
+	// Interval -- the History's interval (note that the actual time between two Data points may differ, see Timestamps)
 	Interval time.Duration
+	// Timestamps -- the time each Data point was taken (for relative TimeSeries: the end of each interval)
+	//
+	// Tickers may drift or skip ticks, so these aren't necessarily Interval apart (optional, see GetTimestamp())
+	Timestamps []time.Time
+
+	// starts -- the start of each interval (only set for relative TimeSeries, see Relative())
+	starts []time.Time
 }
 
+// Value -- returns a single value of a DataPoint (ok is false if the DataPoint doesn't have one, see TimeSeries.Max())
+type Value func(d DataPoint) (value float64, ok bool)
+
+var (
+	// CountValue -- the number of calls (use with relative TimeSeries)
+	CountValue Value = func(d DataPoint) (float64, bool) { return float64(d.Count()), true }
+	// AverageValue -- the average duration of the calls in seconds (use with relative TimeSeries, skips DataPoints without calls)
+	AverageValue Value = func(d DataPoint) (float64, bool) { return d.Average().Seconds(), d.Count() > 0 }
+	// CounterValue -- the value of the key's Counter (its increase for relative TimeSeries)
+	CounterValue Value = func(d DataPoint) (float64, bool) { return float64(d.Counter()), true }
+	// GaugeValue -- the value of the key's Gauge (skips DataPoints where it wasn't set)
+	GaugeValue Value = func(d DataPoint) (float64, bool) { return d.Gauge() }
+)
+
 // GetTimestamp -- returns the time.Time matching the Data point with the given index
+//
+// For relative TimeSeries, that's the end of the index'th interval. If
+// Timestamps isn't set, perfectly regular intervals are assumed.
 func (s *TimeSeries) GetTimestamp(index int) time.Time {
+	if index >= 0 && index < len(s.Timestamps) {
+		return s.Timestamps[index]
+	}
 	return s.StartTS.Add(time.Duration(index) * s.Interval)
 }
 
+// IsRelative -- returns true if this TimeSeries was returned by Relative(), Window() (or Downsample() on one of those)
+func (s *TimeSeries) IsRelative() bool {
+	return s.starts != nil
+}
+
+// intervalStart -- returns the start of the index'th interval of a relative TimeSeries
+func (s *TimeSeries) intervalStart(index int) time.Time {
+	if index < len(s.starts) {
+		return s.starts[index]
+	}
+	return s.GetTimestamp(index).Add(-s.Interval)
+}
+
 // Relative -- returns a relative version of this time series
 //
-// This method simply subtracts each value from the one before it, resulting in a TimeSeries one shorter than this
+// This method simply subtracts each value from the one before it, resulting in a TimeSeries one shorter than this.
 //
 // TimeSeries objects created by History.GetData() contain absolute (and monotonically increasing) values.
 // This makes it easier to calculate aggregates over arbitrary time ranges.
 //
 // This function however returns a list of Snapshot where each item's value is the difference between two absolute Snapshot object
-// (which is the kind of data you'd want to plot with charts).
+// (which is the kind of data you'd want to plot with charts). Each item's timestamp is the end of its interval.
 //
 // The returned list will be one shorter than this one (unless this one is empty or nil in which case nil is returned).
 // Calling this method more than once might result in something resembling the second, third, ... derivatives
 func (s TimeSeries) Relative() TimeSeries {
 	if len(s.Data) == 0 {
+		s.starts = []time.Time{}
 		return s
 	}
 
 	var rc = TimeSeries{
-		Data:     make([]DataPoint, 0, len(s.Data)-1),
-		Interval: s.Interval,
-		Path:     s.Path,
-		StartTS:  s.StartTS, // TODO think about modifying the timestamps (i.e. startTS += interval/2)
+		Data:       make([]DataPoint, 0, len(s.Data)-1),
+		Interval:   s.Interval,
+		Path:       s.Path,
+		StartTS:    s.GetTimestamp(0),
+		Timestamps: make([]time.Time, 0, len(s.Data)-1),
+		starts:     make([]time.Time, 0, len(s.Data)-1),
 	}
 
 	for i := 1; i < len(s.Data); i++ {
 		rc.Data = append(rc.Data, s.Data[i].Sub(s.Data[i-1]))
+		rc.Timestamps = append(rc.Timestamps, s.GetTimestamp(i))
+		rc.starts = append(rc.starts, s.GetTimestamp(i-1))
+	}
+
+	return rc
+}
+
+// Rate -- returns the number of calls per second of each interval
+//
+// Uses the actual time between two Data points (so missed or late ticks don't
+// skew the result). For absolute TimeSeries, the returned slice is one shorter
+// than Data (matching Relative()).
+func (s TimeSeries) Rate() []float64 {
+	if !s.IsRelative() {
+		return s.Relative().Rate()
 	}
 
+	var rc = make([]float64, len(s.Data))
+	for i, d := range s.Data {
+		if seconds := s.GetTimestamp(i).Sub(s.intervalStart(i)).Seconds(); seconds > 0 {
+			rc[i] = float64(d.Count()) / seconds
+		}
+	}
 	return rc
 }
+
+// Sum -- returns the aggregated data of the time range between from and to
+//
+// For absolute TimeSeries, that's the difference between the last Data points
+// taken at (or before) to and from. For relative ones, all the intervals within
+// the range are added up (see Snapshots.Merge() - Active() and Gauge() will
+// be the newest values). The range is clamped to the time span covered by this
+// TimeSeries. Returns nil if there's no data.
+func (s TimeSeries) Sum(from, to time.Time) DataPoint {
+	if !s.IsRelative() {
+		var end = s.indexAt(to)
+		if end < 0 {
+			return nil
+		}
+		var start = s.indexAt(from)
+		if start < 0 {
+			start = 0
+		}
+		return s.Data[end].Sub(s.Data[start])
+	}
+
+	var points []DataPoint
+	for i, d := range s.Data {
+		if !s.intervalStart(i).Before(from) && !s.GetTimestamp(i).After(to) {
+			points = append(points, d)
+		}
+	}
+	if len(points) == 0 {
+		return nil
+	}
+	return sumData(points)
+}
+
+// indexAt -- returns the index of the last Data point taken at (or before) ts (or -1)
+func (s *TimeSeries) indexAt(ts time.Time) int {
+	var rc = -1
+	for i := range s.Data {
+		if s.GetTimestamp(i).After(ts) {
+			break
+		}
+		rc = i
+	}
+	return rc
+}
+
+// Window -- returns a relative TimeSeries where each Data point aggregates the preceding time span d (sliding window)
+//
+//	// number of calls in the last minute (for each point of a History with a one second interval)
+//	var perMinute = history.GetData("http", "GET /").Window(time.Minute)
+//
+// Data points that aren't preceded by (at least) d worth of data are omitted.
+func (s TimeSeries) Window(d time.Duration) TimeSeries {
+	var rc = TimeSeries{
+		Path:     s.Path,
+		Interval: s.Interval,
+		starts:   []time.Time{},
+	}
+
+	for i := range s.Data {
+		var end = s.GetTimestamp(i)
+		var from = end.Add(-d)
+		var point DataPoint
+		var start time.Time
+
+		if !s.IsRelative() {
+			var base = s.indexAt(from)
+			if base < 0 {
+				continue
+			}
+			point, start = s.Data[i].Sub(s.Data[base]), s.GetTimestamp(base)
+		} else {
+			if s.intervalStart(0).After(from) {
+				continue
+			}
+			var first = i
+			for first > 0 && !s.intervalStart(first-1).Before(from) {
+				first--
+			}
+			point, start = sumData(s.Data[first:i+1]), s.intervalStart(first)
+		}
+
+		if len(rc.Data) == 0 {
+			rc.StartTS = start
+		}
+		rc.Data = append(rc.Data, point)
+		rc.Timestamps = append(rc.Timestamps, end)
+		rc.starts = append(rc.starts, start)
+	}
+	return rc
+}
+
+// Downsample -- returns a TimeSeries with (at most) n Data points (e.g. for charts)
+//
+// Absolute TimeSeries simply keep every k-th Data point, relative ones
+// combine k consecutive intervals (see Sum()). The newest Data point is always
+// kept (which means the oldest group of a relative TimeSeries may be smaller).
+func (s TimeSeries) Downsample(n int) TimeSeries {
+	if n <= 0 || len(s.Data) <= n {
+		return s
+	}
+
+	var k = (len(s.Data) + n - 1) / n
+	var rc = TimeSeries{
+		Path:     s.Path,
+		Interval: s.Interval * time.Duration(k),
+	}
+	if s.IsRelative() {
+		rc.starts = []time.Time{}
+	}
+
+	// groups of k Data points, aligned to the newest one
+	for end := (len(s.Data) - 1) % k; end < len(s.Data); end += k {
+		var start = end - k + 1
+		if start < 0 {
+			start = 0
+		}
+
+		if s.IsRelative() {
+			rc.Data = append(rc.Data, sumData(s.Data[start:end+1]))
+			rc.starts = append(rc.starts, s.intervalStart(start))
+		} else {
+			rc.Data = append(rc.Data, s.Data[end])
+		}
+		rc.Timestamps = append(rc.Timestamps, s.GetTimestamp(end))
+	}
+
+	rc.StartTS = rc.Timestamps[0]
+	if s.IsRelative() {
+		rc.StartTS = rc.starts[0]
+	}
+	return rc
+}
+
+// Max -- returns the largest of the given values (ok is false if there are none)
+//
+//	var busiest, _ = history.GetData("http").Relative().Max(faster.CountValue)
+func (s TimeSeries) Max(value Value) (max float64, ok bool) {
+	for _, d := range s.Data {
+		if v, has := value(d); has && (!ok || v > max) {
+			max, ok = v, true
+		}
+	}
+	return max, ok
+}
+
+// Min -- returns the smallest of the given values (ok is false if there are none)
+func (s TimeSeries) Min(value Value) (min float64, ok bool) {
+	for _, d := range s.Data {
+		if v, has := value(d); has && (!ok || v < min) {
+			min, ok = v, true
+		}
+	}
+	return min, ok
+}
+
+// Mean -- returns the arithmetic mean of the given values (ok is false if there are none)
+func (s TimeSeries) Mean(value Value) (mean float64, ok bool) {
+	var sum float64
+	var count int
+	for _, d := range s.Data {
+		if v, has := value(d); has {
+			sum += v
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return sum / float64(count), true
+}
+
+// sumData -- adds up the given (relative) DataPoints (keeping the newest Active() and Gauge() values)
+func sumData(points []DataPoint) DataPoint {
+	var rc data
+	for _, d := range points {
+		rc.active = d.Active()
+		rc.count += d.Count()
+		rc.totalTime += d.TotalTime()
+		rc.counter += d.Counter()
+		rc.allocCalls += d.AllocCalls()
+		rc.allocs += d.Allocs()
+		rc.allocBytes += d.AllocBytes()
+
+		if gauge, ok := d.Gauge(); ok {
+			rc.gauge = gauge
+			rc.kind |= kindGauge
+		}
+		if d.Counter() != 0 {
+			rc.kind |= kindCounter
+		}
+		if d.AllocCalls() != 0 {
+			rc.kind |= kindAllocs
+		}
+	}
+	return &rc
+}
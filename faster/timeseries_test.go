@@ -0,0 +1,151 @@
+package faster_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mreithub/go-faster/faster"
+	"github.com/mreithub/go-faster/faster/fastertest"
+	"github.com/stretchr/testify/assert"
+)
+
+// testSeries -- returns the absolute TimeSeries of a key with 2, 4, 6 and 8 calls (taking 1ms each) per interval
+//
+// The History's interval is one second, but the fourth tick was missed (-> snapshots at 0, 1, 2, 3 and 5 seconds)
+func testSeries(t *testing.T) (faster.TimeSeries, time.Time) {
+	var f, clock = fastertest.NewWithClock(t)
+	var start = clock.Now()
+	var h = faster.NewManualHistory("test", time.Second, 10)
+
+	h.Push(f.TakeSnapshot()) // the key doesn't exist yet
+	for i := 1; i <= 4; i++ {
+		for j := 0; j < 2*i; j++ {
+			var ref = f.Track("http")
+			clock.Advance(time.Millisecond)
+			ref.Done()
+		}
+
+		var elapsed = clock.Now().Sub(start)
+		var next = time.Duration(i) * time.Second
+		if i == 4 {
+			next += time.Second // missed tick
+		}
+		clock.Advance(next - elapsed)
+		h.Push(f.TakeSnapshot())
+	}
+
+	var series = h.GetData("http")
+	assert.Len(t, h.GetData("nonexistent").Data, 0)
+	assert.Len(t, h.GetData("nonexistent").Rate(), 0)
+	return series, start
+}
+
+func TestTimeSeriesGaps(t *testing.T) {
+	var series, start = testSeries(t)
+
+	// snapshots without the key count as empty, timestamps are the actual ones
+	if assert.Len(t, series.Data, 5) {
+		assert.Equal(t, int64(0), series.Data[0].Count())
+		assert.Equal(t, int64(20), series.Data[4].Count())
+		assert.Equal(t, start.Add(5*time.Second), series.GetTimestamp(4))
+	}
+
+	var rel = series.Relative()
+	assert.True(t, rel.IsRelative())
+	assert.False(t, series.IsRelative())
+	if assert.Len(t, rel.Data, 4) {
+		assert.Equal(t, int64(2), rel.Data[0].Count())
+		assert.Equal(t, start.Add(time.Second), rel.GetTimestamp(0))
+	}
+
+	// calls per second (the last interval took 2 seconds)
+	assert.Equal(t, []float64{2, 4, 6, 4}, series.Rate())
+	assert.Equal(t, series.Rate(), rel.Rate())
+}
+
+func TestTimeSeriesSum(t *testing.T) {
+	var series, start = testSeries(t)
+	var rel = series.Relative()
+
+	for _, s := range []faster.TimeSeries{series, rel} {
+		var sum = s.Sum(start.Add(time.Second), start.Add(3*time.Second))
+		assert.Equal(t, int64(10), sum.Count())
+		assert.Equal(t, 10*time.Millisecond, sum.TotalTime())
+
+		// clamped to the available data
+		assert.Equal(t, int64(20), s.Sum(start.Add(-time.Hour), start.Add(time.Hour)).Count())
+		assert.Nil(t, s.Sum(start.Add(-time.Hour), start.Add(-time.Minute)))
+	}
+
+	// relative Sums keep the newest Gauge value
+	var h = faster.NewManualHistory("test", time.Second, 10)
+	var f = faster.New()
+	for i := 1; i <= 3; i++ {
+		f.Gauge("g").Set(float64(i))
+		h.Push(f.TakeSnapshot())
+	}
+	var gauges = h.GetData("g").Relative()
+	var gauge, ok = gauges.Sum(time.Time{}, time.Now().Add(time.Hour)).Gauge()
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, gauge)
+}
+
+func TestTimeSeriesWindow(t *testing.T) {
+	var series, start = testSeries(t)
+
+	// number of calls in the last two seconds
+	for _, s := range []faster.TimeSeries{series, series.Relative()} {
+		var w = s.Window(2 * time.Second)
+		assert.True(t, w.IsRelative())
+		if assert.Len(t, w.Data, 3) {
+			assert.Equal(t, int64(6), w.Data[0].Count())
+			assert.Equal(t, start.Add(2*time.Second), w.GetTimestamp(0))
+			assert.Equal(t, int64(10), w.Data[1].Count())
+			assert.Equal(t, int64(8), w.Data[2].Count()) // only the last (two seconds) interval
+		}
+		assert.Equal(t, []float64{3, 5, 4}, w.Rate())
+	}
+}
+
+func TestTimeSeriesDownsample(t *testing.T) {
+	var series, start = testSeries(t)
+
+	var down = series.Downsample(3)
+	if assert.Len(t, down.Data, 3) {
+		assert.Equal(t, int64(0), down.Data[0].Count())
+		assert.Equal(t, int64(6), down.Data[1].Count())
+		assert.Equal(t, int64(20), down.Data[2].Count())
+		assert.Equal(t, start.Add(5*time.Second), down.GetTimestamp(2))
+	}
+
+	down = series.Relative().Downsample(2)
+	if assert.Len(t, down.Data, 2) {
+		assert.Equal(t, int64(6), down.Data[0].Count())
+		assert.Equal(t, int64(14), down.Data[1].Count())
+		assert.Equal(t, start.Add(5*time.Second), down.GetTimestamp(1))
+	}
+	assert.Equal(t, []float64{3, 14.0 / 3}, down.Rate())
+
+	assert.Len(t, series.Downsample(10).Data, 5)
+}
+
+func TestTimeSeriesAggregates(t *testing.T) {
+	var series, _ = testSeries(t)
+	var rel = series.Relative()
+
+	var max, ok = rel.Max(faster.CountValue)
+	assert.True(t, ok)
+	assert.Equal(t, 8.0, max)
+	var min, _ = rel.Min(faster.CountValue)
+	assert.Equal(t, 2.0, min)
+	var mean, _ = rel.Mean(faster.CountValue)
+	assert.Equal(t, 5.0, mean)
+	mean, _ = rel.Mean(faster.AverageValue)
+	assert.Equal(t, time.Millisecond.Seconds(), mean)
+
+	// values DataPoints don't have are skipped
+	_, ok = rel.Max(faster.GaugeValue)
+	assert.False(t, ok)
+	_, ok = faster.TimeSeries{}.Mean(faster.CountValue)
+	assert.False(t, ok)
+}
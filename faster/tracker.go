@@ -1,6 +1,7 @@
 package faster
 
 import (
+	"context"
 	"time"
 
 	"github.com/mreithub/go-faster/faster/internal"
@@ -15,57 +16,154 @@ type Tracker struct {
 	path    []string
 	startTS time.Time
 	took    time.Duration
+
+	// if set, the outcome of this context will be recorded on Done() (see WatchContext())
+	ctx context.Context
+	// creation stack (only captured if enabled - see Faster.SetCaptureStacks())
+	stack []uintptr
+	// attributes stored with slow call Exemplars (see SetAttr())
+	attrs map[string]string
+	// set (atomically) if this Tracker's EvDone was dropped (see DropWhenFull)
+	doneDropped int32
+	// Faster.generation at the time its EvTrack was processed (0 if it wasn't, only accessed by the run() goroutine)
+	generation uint32
+	// number of calls this Tracker stands for (1 unless sampled, see Sampler)
+	weight int64
+	// set for calls that weren't sampled (Done() won't record anything, see Sampled())
+	sampledOut bool
+	// heap allocation counters at creation time (only set by TrackAlloc())
+	allocStart *allocStats
+	// heap allocations between creation and Done() (only measured if allocStart is set)
+	allocs allocStats
 }
 
 // Done -- Dereference an instance of 'key'
 //
 // it is safe to call Done() more than once (from the same goroutine - this struct is NOT thread safe)
+// or on a nil Tracker
 func (t *Tracker) Done() {
-	if t.parent == nil {
+	if t == nil || t.parent == nil {
 		//log.Print("go-faster warning: possible double Done()")
 		return
 	}
+	if t.sampledOut {
+		t.parent = nil
+		return
+	}
+	if t.allocStart != nil {
+		var allocs = readAllocs()
+		t.allocs = allocStats{
+			objects: allocs.objects - t.allocStart.objects,
+			bytes:   allocs.bytes - t.allocStart.bytes,
+		}
+	}
 
 	var now time.Time
 	var took time.Duration
 	if !t.startTS.IsZero() {
 		// only measure time if startTime was set
-		now = time.Now()
+		now = t.parent.clock.Now()
 		took = now.Sub(t.startTS)
 	}
 	t.took = took
 
-	t.parent.do(internal.EvDone, t.path, took)
+	t.parent.doTracker(internal.EvDone, t, took)
+	if t.ctx != nil {
+		t.recordContextErr(t.ctx.Err(), took)
+	}
 	t.parent = nil // prevent double Done()
 }
 
+// recordContextErr -- tracks a '_canceled' or '_deadlineExceeded' child of this Tracker's path (if err is one of those)
+func (t *Tracker) recordContextErr(err error, took time.Duration) {
+	var name string
+	switch err {
+	case nil:
+		return
+	case context.Canceled:
+		name = "_canceled"
+	case context.DeadlineExceeded:
+		name = "_deadlineExceeded"
+	default:
+		name = "_contextError"
+	}
+
+	var path = make([]string, 0, len(t.path)+1)
+	path = append(path, t.path...)
+	path = append(path, name)
+
+	t.parent.doCall(path, took, t.weight)
+}
+
 // NewChild -- creates a child with the same startTS and backing Faster instance but different path
 //
 // won't work after Done() was called on this object (will return nil)
 //
 // Make sure to call Done() on each child you create here
 func (t *Tracker) NewChild(path ...string) *Tracker {
-	if t.parent == nil {
+	if t == nil || t.parent == nil {
 		return nil
 	}
+	// copy the path (appending to t.path directly might share its backing array with other children)
+	var childPath = make([]string, 0, len(t.path)+len(path))
+	childPath = append(append(childPath, t.path...), path...)
+	if t.sampledOut {
+		return notSampled(t.parent, childPath)
+	}
 
 	var rc = Tracker{
 		parent:  t.parent,
-		path:    append(t.path, path...),
+		path:    childPath,
 		startTS: t.startTS,
+		ctx:     t.ctx,
+		stack:   t.stack,
+		weight:  t.weight,
+	}
+	if t.allocStart != nil {
+		var start = *t.allocStart
+		rc.allocStart = &start
 	}
-	t.parent.do(internal.EvTrack, rc.path, 0)
+	for k, v := range t.attrs {
+		rc.SetAttr(k, v)
+	}
+	t.parent.doTracker(internal.EvTrack, &rc, 0)
 
 	return &rc
 }
 
+// WatchContext -- makes Done() record the outcome of ctx
+//
+// If ctx was canceled (or its deadline exceeded) by the time Done() is called, an
+// additional '_canceled' (or '_deadlineExceeded') child key will be tracked
+// (with the same duration).
+//
+// Returns the Tracker itself (allowing for `defer faster.Track("foo").WatchContext(ctx).Done()`)
+func (t *Tracker) WatchContext(ctx context.Context) *Tracker {
+	if t == nil {
+		return nil
+	}
+	t.ctx = ctx
+	return t
+}
+
+// Sampled -- returns false if the call wasn't sampled (see WithSampling() and Sampler) - in which case Done() won't record anything
+func (t *Tracker) Sampled() bool {
+	return t != nil && !t.sampledOut
+}
+
 // Path -- returns the Faster path this Tracker object is bound to
 func (t *Tracker) Path() []string {
+	if t == nil {
+		return nil
+	}
 	return t.path
 }
 
 // StartTS -- Returns the timestamp of this Tracker object's creation
 func (t *Tracker) StartTS() time.Time {
+	if t == nil {
+		return time.Time{}
+	}
 	return t.startTS
 }
 
@@ -73,5 +171,8 @@ func (t *Tracker) StartTS() time.Time {
 //
 // before Done() is called, this getter will return 0
 func (t *Tracker) Took() time.Duration {
+	if t == nil {
+		return 0
+	}
 	return t.took
 }
@@ -0,0 +1,166 @@
+package faster
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Transport -- http.RoundTripper tracking outgoing HTTP requests
+//
+// Requests are tracked as [Prefix..., host, route] (until their response body has been
+// read entirely or closed), with the following child keys:
+//
+// - the response's status class ("2xx", "4xx", ...) or "_error" if the request failed
+// - "_ttfb": time to the response's first byte
+// - "_newConn" or "_reusedConn": time it took to get a connection (and whether it was reused or not)
+//
+// The zero value is ready to use (tracking requests in the Singleton instance, using http.DefaultTransport)
+type Transport struct {
+	// Base -- the underlying RoundTripper (http.DefaultTransport if nil)
+	Base http.RoundTripper
+	// Faster -- the instance to track requests in (Singleton if nil)
+	Faster *Faster
+	// Prefix -- key prefix (defaults to "http-client")
+	Prefix []string
+	// Route -- returns the route template for the given request (e.g. "GET /users/{id}")
+	//
+	// Defaults to the request method (as raw URL paths might cause a huge number of keys)
+	Route func(r *http.Request) string
+}
+
+// RoundTrip -- implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var f, base = t.Faster, t.Base
+	if f == nil {
+		f = Singleton
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	var prefix = t.Prefix
+	if prefix == nil {
+		prefix = []string{"http-client"}
+	}
+	var route = req.Method
+	if t.Route != nil {
+		route = t.Route(req)
+	}
+
+	var path = make([]string, 0, len(prefix)+2)
+	path = append(path, prefix...)
+	path = append(path, req.URL.Host, route)
+
+	var rt = roundTrip{
+		faster: f,
+		ref:    f.Track(path...),
+	}
+	var trace = httptrace.ClientTrace{
+		GotConn:              rt.gotConn,
+		GotFirstResponseByte: rt.gotFirstResponseByte,
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &trace))
+
+	var resp, err = base.RoundTrip(req)
+	if err != nil {
+		rt.done("_error")
+		return nil, err
+	}
+
+	var statusClass = strconv.Itoa(resp.StatusCode/100) + "xx"
+	if resp.Body == nil || resp.Body == http.NoBody || resp.StatusCode == http.StatusSwitchingProtocols {
+		// upgraded connections (e.g. WebSockets) need their io.ReadWriteCloser body (and outlive the request anyway)
+		rt.done(statusClass)
+	} else {
+		resp.Body = &trackedBody{
+			ReadCloser: resp.Body,
+			done:       func() { rt.done(statusClass) },
+		}
+	}
+	return resp, nil
+}
+
+// roundTrip -- state of a single tracked request (httptrace callbacks may be called from other goroutines)
+type roundTrip struct {
+	faster *Faster
+	ref    *Tracker
+
+	lock     sync.Mutex
+	connTS   time.Time
+	reused   bool
+	ttfbTS   time.Time
+	finished bool
+}
+
+func (r *roundTrip) gotConn(info httptrace.GotConnInfo) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.connTS = r.faster.clock.Now()
+	r.reused = info.Reused
+}
+
+func (r *roundTrip) gotFirstResponseByte() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.ttfbTS = r.faster.clock.Now()
+}
+
+// done -- finishes the request's Tracker (tracking the child keys) - only the first call has any effect
+func (r *roundTrip) done(result string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.finished {
+		return
+	}
+	r.finished = true
+
+	var ref = r.ref
+	ref.Done()
+	if !ref.Sampled() {
+		return // not sampled (see WithSampling())
+	}
+	r.trackChild(result, ref.Took())
+	if !r.connTS.IsZero() {
+		var name = "_newConn"
+		if r.reused {
+			name = "_reusedConn"
+		}
+		r.trackChild(name, r.connTS.Sub(ref.StartTS()))
+	}
+	if !r.ttfbTS.IsZero() {
+		r.trackChild("_ttfb", r.ttfbTS.Sub(ref.StartTS()))
+	}
+}
+
+// trackChild -- tracks a (finished) child of the request's key
+func (r *roundTrip) trackChild(name string, took time.Duration) {
+	var path = make([]string, 0, len(r.ref.path)+1)
+	path = append(path, r.ref.path...)
+	path = append(path, name)
+
+	r.faster.doCall(path, took, r.ref.weight)
+}
+
+// trackedBody -- wraps a response body (calling done() on EOF or Close())
+type trackedBody struct {
+	io.ReadCloser
+	done func()
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	var n, err = b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.done()
+	}
+	return n, err
+}
+
+func (b *trackedBody) Close() error {
+	var err = b.ReadCloser.Close()
+	b.done()
+	return err
+}
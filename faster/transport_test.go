@@ -0,0 +1,136 @@
+package faster
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransport(t *testing.T) {
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("hello world"))
+	}))
+	defer srv.Close()
+
+	var f = New()
+	var client = http.Client{
+		Transport: &Transport{
+			Faster: f,
+			Route: func(r *http.Request) string {
+				return r.Method + " " + r.URL.Path
+			},
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		var resp, err = client.Get(srv.URL + "/")
+		assert.NoError(t, err)
+		var body, _ = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "hello world", string(body))
+	}
+
+	var resp, err = client.Get(srv.URL + "/notFound")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	var host = srv.Listener.Addr().String()
+	var snap = f.TakeSnapshot()
+	assert.Equal(t, int64(2), snap.Get("http-client", host, "GET /").Count())
+	assert.Equal(t, int64(2), snap.Get("http-client", host, "GET /", "2xx").Count())
+	assert.Equal(t, int64(2), snap.Get("http-client", host, "GET /", "_ttfb").Count())
+	assert.Equal(t, int64(1), snap.Get("http-client", host, "GET /", "_newConn").Count())
+	assert.Equal(t, int64(1), snap.Get("http-client", host, "GET /", "_reusedConn").Count())
+
+	// the 404 response's body hasn't been closed yet
+	assert.Equal(t, int32(1), snap.Get("http-client", host, "GET /notFound").Active())
+	assert.Nil(t, snap.Get("http-client", host, "GET /notFound", "4xx"))
+	resp.Body.Close()
+	resp.Body.Close() // calling Close() twice shouldn't count twice
+	snap = f.TakeSnapshot()
+	assert.Equal(t, int32(0), snap.Get("http-client", host, "GET /notFound").Active())
+	assert.Equal(t, int64(1), snap.Get("http-client", host, "GET /notFound").Count())
+	assert.Equal(t, int64(1), snap.Get("http-client", host, "GET /notFound", "4xx").Count())
+}
+
+func TestTransportError(t *testing.T) {
+	var srv = httptest.NewServer(http.NotFoundHandler())
+	var addr, _ = url.Parse(srv.URL)
+	srv.Close()
+
+	var f = New()
+	var client = http.Client{Transport: &Transport{Faster: f}}
+	var _, err = client.Get(addr.String())
+	assert.Error(t, err)
+
+	var snap = f.TakeSnapshot()
+	assert.Equal(t, int64(1), snap.Get("http-client", addr.Host, "GET").Count())
+	assert.Equal(t, int64(1), snap.Get("http-client", addr.Host, "GET", "_error").Count())
+	assert.Nil(t, snap.Get("http-client", addr.Host, "GET", "_ttfb"))
+}
+
+func TestTransportSampled(t *testing.T) {
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	var f = New(WithSampling(SampleEvery, 2))
+	var client = http.Client{Transport: &Transport{Faster: f}}
+	for i := 0; i < 4; i++ {
+		var resp, err = client.Get(srv.URL + "/")
+		if assert.NoError(t, err) {
+			resp.Body.Close()
+		}
+	}
+
+	// children are scaled up like their parent
+	var snap = f.TakeSnapshot()
+	var host = srv.Listener.Addr().String()
+	assert.Equal(t, int64(4), snap.Get("http-client", host, "GET").Count())
+	assert.Equal(t, int64(4), snap.Get("http-client", host, "GET", "2xx").Count())
+}
+
+// roundTripFunc -- http.RoundTripper returning a fixed response
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return fn(req) }
+
+// upgradedConn -- io.ReadWriteCloser body of a 101 Switching Protocols response
+type upgradedConn struct{ io.ReadWriter }
+
+func (upgradedConn) Close() error { return nil }
+
+func TestTransportUpgrade(t *testing.T) {
+	var f = New()
+	var client = http.Client{Transport: &Transport{
+		Faster: f,
+		Base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusSwitchingProtocols,
+				Body:       upgradedConn{new(bytes.Buffer)},
+				Request:    req,
+			}, nil
+		}),
+	}}
+
+	var resp, err = client.Get("http://example.com/ws")
+	if !assert.NoError(t, err) {
+		return
+	}
+	var _, ok = resp.Body.(io.ReadWriteCloser)
+	assert.True(t, ok, "the upgraded connection's body has to stay writable")
+
+	// tracked right away (the connection's lifetime isn't part of the request)
+	var snap = f.TakeSnapshot()
+	assert.Equal(t, int64(1), snap.Get("http-client", "example.com", "GET", "1xx").Count())
+	assert.Equal(t, int32(0), snap.Get("http-client", "example.com", "GET").Active())
+}